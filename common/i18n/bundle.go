@@ -0,0 +1,102 @@
+// Package i18n resolves translation keys with named parameters against per-language message bundles.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Bundle holds the translated messages of each language, keyed by translation key.
+// Messages use named placeholders, e.g. "Wallet {address} not found". A Bundle is immutable
+// and safe for concurrent use.
+type Bundle struct {
+	fallback language.Tag
+	tags     []language.Tag // fallback first, so that it is the default match
+	messages map[language.Tag]map[string]string
+	matcher  language.Matcher
+}
+
+// NewBundle creates a Bundle from the messages of each language. Keys missing in the requested
+// language, and requests without a supported language, fall back to English.
+func NewBundle(messages map[language.Tag]map[string]string) *Bundle {
+	b := &Bundle{
+		fallback: language.English,
+		tags:     []language.Tag{language.English},
+		messages: make(map[language.Tag]map[string]string, len(messages)),
+	}
+	for tag, msgs := range messages {
+		b.messages[tag] = msgs
+		if tag != b.fallback {
+			b.tags = append(b.tags, tag)
+		}
+	}
+	b.matcher = language.NewMatcher(b.tags)
+	return b
+}
+
+// LoadBundle loads the JSON message files of dir. Each file is named after a BCP 47 language tag
+// (en.json, fr.json, pt-BR.json) and holds a flat object of translation keys to messages.
+func LoadBundle(dir string) (*Bundle, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message files: %w", err)
+	}
+
+	messages := make(map[language.Tag]map[string]string, len(paths))
+	for _, path := range paths {
+		tag, parseErr := language.Parse(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+		if parseErr != nil {
+			return nil, fmt.Errorf("invalid language of message file %s: %w", path, parseErr)
+		}
+
+		data, readErr := os.ReadFile(path)
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read message file %s: %w", path, readErr)
+		}
+
+		var msgs map[string]string
+		if unmarshalErr := json.Unmarshal(data, &msgs); unmarshalErr != nil {
+			return nil, fmt.Errorf("failed to parse message file %s: %w", path, unmarshalErr)
+		}
+		messages[tag] = msgs
+	}
+
+	return NewBundle(messages), nil
+}
+
+// Localize resolves key for the preferred languages of an Accept-Language header value and substitutes
+// the named params. It returns the language of the message, and false when the key is unknown both in the
+// matched language and in English.
+func (b *Bundle) Localize(acceptLanguage, key string, params map[string]string) (string, language.Tag, bool) {
+	preferred, _, _ := language.ParseAcceptLanguage(acceptLanguage) // invalid headers fall back to English
+	_, idx, _ := b.matcher.Match(preferred...)
+	tag := b.tags[idx]
+
+	msg, ok := b.messages[tag][key]
+	if !ok && tag != b.fallback {
+		tag = b.fallback
+		msg, ok = b.messages[tag][key]
+	}
+	if !ok {
+		return "", language.Und, false
+	}
+
+	return substitute(msg, params), tag, true
+}
+
+// substitute replaces the {name} placeholders of msg with params. Unknown placeholders are left as is.
+func substitute(msg string, params map[string]string) string {
+	if len(params) == 0 {
+		return msg
+	}
+	oldnew := make([]string, 0, 2*len(params))
+	for name, value := range params {
+		oldnew = append(oldnew, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(oldnew...).Replace(msg)
+}
@@ -0,0 +1,63 @@
+package i18n_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+
+	"github.com/rainbow-me/platform-tools/common/i18n"
+)
+
+func TestBundle_Localize(t *testing.T) {
+	bundle, err := i18n.LoadBundle("testdata")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		key            string
+		wantMessage    string
+		wantTag        language.Tag
+		wantOK         bool
+	}{
+		{
+			name:           "regional match",
+			acceptLanguage: "pt-BR,pt;q=0.9",
+			key:            "wallet.not_found",
+			wantMessage:    "Carteira 0xabc não encontrada",
+			wantTag:        language.BrazilianPortuguese,
+			wantOK:         true,
+		},
+		{
+			name:           "missing key falls back to English",
+			acceptLanguage: "pt-BR",
+			key:            "wallet.locked",
+			wantMessage:    "Wallet is locked",
+			wantTag:        language.English,
+			wantOK:         true,
+		},
+		{
+			name:        "empty header uses English",
+			key:         "wallet.not_found",
+			wantMessage: "Wallet 0xabc not found",
+			wantTag:     language.English,
+			wantOK:      true,
+		},
+		{
+			name:           "unknown key",
+			acceptLanguage: "en",
+			key:            "wallet.unknown",
+			wantTag:        language.Und,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, tag, ok := bundle.Localize(tt.acceptLanguage, tt.key, map[string]string{"address": "0xabc"})
+			require.Equal(t, tt.wantOK, ok)
+			require.Equal(t, tt.wantMessage, msg)
+			require.Equal(t, tt.wantTag, tag)
+		})
+	}
+}
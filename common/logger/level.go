@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	// atomicLevel is the level of the logger created by Init, shared by all the loggers derived from it
+	atomicLevel = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+
+	levelMu       sync.Mutex
+	revertTimer   *time.Timer // Pending revert of a temporary level
+	revertAt      time.Time   // When the temporary level reverts, zero if none
+	revertTo      Level       // Level restored by the pending revert
+	toggledFrom   Level       // Level before ToggleDebug switched to debug
+	debugToggled  bool        // Whether ToggleDebug switched to debug
+	levelRevision uint64      // Incremented on every change, so that stale reverts are ignored
+)
+
+// String returns the lower-case name of the level, e.g. "debug"
+func (l Level) String() string {
+	return zapcore.Level(l).String()
+}
+
+// GetLevel returns the current level of the logger created by Init
+func GetLevel() Level {
+	return Level(atomicLevel.Level())
+}
+
+// SetLevel changes the level of the logger created by Init and every logger derived from it, cancelling any
+// pending revert. It returns the previous level.
+func SetLevel(lvl Level) Level {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	return setLevelLocked(lvl)
+}
+
+// SetLevelFor changes the level for the given duration, then reverts to the level in place before the first
+// pending temporary change: nested calls extend or shorten the temporary level without reverting to another
+// temporary one. A later SetLevel or ToggleDebug cancels the revert. It returns the previous level.
+func SetLevelFor(lvl Level, duration time.Duration) Level {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	target := revertTo
+	pending := !revertAt.IsZero()
+	previous := setLevelLocked(lvl)
+	if !pending {
+		target = previous
+	}
+	revision := levelRevision
+	revertAt, revertTo = time.Now().Add(duration), target
+	revertTimer = time.AfterFunc(duration, func() {
+		levelMu.Lock()
+		defer levelMu.Unlock()
+		if levelRevision != revision {
+			return // Changed again since
+		}
+		setLevelLocked(target)
+		logLevelChange("Log level reverted", target)
+	})
+	return previous
+}
+
+// ToggleDebug switches the level to debug, or back to the level in place before the previous toggle.
+// It returns the new level.
+func ToggleDebug() Level {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	if debugToggled && GetLevel() == DebugLevel {
+		setLevelLocked(toggledFrom)
+		return toggledFrom
+	}
+	previous := setLevelLocked(DebugLevel)
+	toggledFrom, debugToggled = previous, true
+	return DebugLevel
+}
+
+// ToggleDebugOnSignal calls ToggleDebug every time one of the signals is received, e.g. syscall.SIGUSR1,
+// until ctx is done.
+func ToggleDebugOnSignal(ctx context.Context, signals ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-ch:
+				logLevelChange("Log level toggled by signal "+sig.String(), ToggleDebug())
+			}
+		}
+	}()
+}
+
+// setLevelLocked sets the level and cancels the pending revert; levelMu must be held.
+func setLevelLocked(lvl Level) Level {
+	previous := GetLevel()
+	atomicLevel.SetLevel(zapcore.Level(lvl))
+	levelRevision++
+	debugToggled = false
+	revertAt = time.Time{}
+	if revertTimer != nil {
+		revertTimer.Stop()
+		revertTimer = nil
+	}
+	return previous
+}
+
+func logLevelChange(msg string, lvl Level) {
+	if zLog != nil {
+		zLog.Warn(msg, String("level", lvl.String()))
+	}
+}
+
+// LevelState is the body of the LevelHandler responses
+type LevelState struct {
+	Level    string     `json:"level"`
+	RevertAt *time.Time `json:"revert_at,omitempty"` // When a temporary level reverts
+}
+
+// LevelRequest is the body of the LevelHandler PUT requests
+type LevelRequest struct {
+	Level    string `json:"level"`              // New level, e.g. "debug"
+	Duration string `json:"duration,omitempty"` // Optional time after which the previous level is restored, e.g. "15m"
+}
+
+// LevelHandler returns an HTTP handler reporting the current level on GET and changing it on PUT with a
+// LevelRequest body, temporarily when it has a duration.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			if err := changeLevel(r); err != nil {
+				writeLevelResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeLevelResponse(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		writeLevelResponse(w, http.StatusOK, currentLevelState())
+	})
+}
+
+func changeLevel(r *http.Request) error {
+	var req LevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.Wrap(err, "invalid body")
+	}
+	lvl, ok := LevelFromString(req.Level)
+	if !ok {
+		return errors.Newf("unknown level %q", req.Level)
+	}
+	if req.Duration == "" {
+		SetLevel(lvl)
+		logLevelChange("Log level changed", lvl)
+		return nil
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 {
+		return errors.Newf("invalid duration %q", req.Duration)
+	}
+	SetLevelFor(lvl, duration)
+	logLevelChange("Log level changed until "+time.Now().Add(duration).Format(time.RFC3339), lvl)
+	return nil
+}
+
+func currentLevelState() LevelState {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	state := LevelState{Level: GetLevel().String()}
+	if !revertAt.IsZero() {
+		at := revertAt
+		state.RevertAt = &at
+	}
+	return state
+}
+
+func writeLevelResponse(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,91 @@
+package logger_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rainbow-me/platform-tools/common/logger"
+)
+
+func TestSetLevel(t *testing.T) {
+	t.Cleanup(func() { logger.SetLevel(logger.InfoLevel) })
+
+	logger.SetLevel(logger.InfoLevel)
+	require.Equal(t, logger.InfoLevel, logger.SetLevel(logger.WarnLevel))
+	require.Equal(t, logger.WarnLevel, logger.GetLevel())
+
+	// Temporary level, reverted after the duration
+	require.Equal(t, logger.WarnLevel, logger.SetLevelFor(logger.DebugLevel, 20*time.Millisecond))
+	require.Equal(t, logger.DebugLevel, logger.GetLevel())
+	require.Eventually(t, func() bool { return logger.GetLevel() == logger.WarnLevel }, time.Second, 5*time.Millisecond)
+
+	// Nested temporary changes revert to the level before the first one
+	logger.SetLevelFor(logger.DebugLevel, 20*time.Millisecond)
+	require.Equal(t, logger.DebugLevel, logger.SetLevelFor(logger.ErrorLevel, 20*time.Millisecond))
+	require.Eventually(t, func() bool { return logger.GetLevel() == logger.WarnLevel }, time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, logger.WarnLevel, logger.GetLevel())
+
+	// A later change cancels the revert
+	logger.SetLevelFor(logger.DebugLevel, 20*time.Millisecond)
+	logger.SetLevel(logger.ErrorLevel)
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, logger.ErrorLevel, logger.GetLevel())
+}
+
+func TestToggleDebug(t *testing.T) {
+	t.Cleanup(func() { logger.SetLevel(logger.InfoLevel) })
+
+	logger.SetLevel(logger.WarnLevel)
+	require.Equal(t, logger.DebugLevel, logger.ToggleDebug())
+	require.Equal(t, logger.WarnLevel, logger.ToggleDebug())
+	require.Equal(t, logger.WarnLevel, logger.GetLevel())
+}
+
+func TestLevelHandler(t *testing.T) {
+	t.Cleanup(func() { logger.SetLevel(logger.InfoLevel) })
+	logger.SetLevel(logger.InfoLevel)
+	handler := logger.LevelHandler()
+
+	serve := func(method, body string) (int, logger.LevelState) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, "/debug/loglevel", strings.NewReader(body)))
+		var state logger.LevelState
+		_ = json.NewDecoder(w.Body).Decode(&state)
+		return w.Code, state
+	}
+
+	code, state := serve(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "info", state.Level)
+	require.Nil(t, state.RevertAt)
+
+	code, state = serve(http.MethodPut, `{"level":"debug","duration":"1h"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "debug", state.Level)
+	require.NotNil(t, state.RevertAt)
+	require.WithinDuration(t, time.Now().Add(time.Hour), *state.RevertAt, time.Minute)
+
+	code, state = serve(http.MethodPut, `{"level":"warn"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "warn", state.Level)
+	require.Nil(t, state.RevertAt)
+
+	code, _ = serve(http.MethodPut, `{"level":"verbose"}`)
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = serve(http.MethodPut, `{"level":"debug","duration":"soon"}`)
+	require.Equal(t, http.StatusBadRequest, code)
+	for _, method := range []string{http.MethodPost, http.MethodDelete} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, "/debug/loglevel", strings.NewReader(`{"level":"debug"}`)))
+		require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		require.Equal(t, "GET, PUT", w.Header().Get("Allow"))
+	}
+	require.Equal(t, logger.WarnLevel, logger.GetLevel())
+}
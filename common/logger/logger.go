@@ -34,7 +34,8 @@ var (
 // and unstructured logging. It is environment aware and customizes options accordingly.
 // For example, it will print human-readable, tab-separated log lines for local environment and json logs in non-local.
 // By default, the log level is DEBUG in all nonprod environments, and INFO in prod.
-// Such behaviour cna be overridden by setting the LOG_LEVEL env var, see LevelFromString.
+// Such behaviour cna be overridden by setting the LOG_LEVEL env var, see LevelFromString, and changed at runtime
+// with SetLevel, LevelHandler or ToggleDebugOnSignal.
 type Logger struct {
 	root    *zap.Logger
 	zap     *zap.Logger
@@ -257,6 +258,10 @@ func newZapLogger(environment env.Environment) (*Logger, error) {
 		config.Level.SetLevel(zapcore.Level(lvl))
 	}
 
+	// Share the level with GetLevel/SetLevel, so that it can change at runtime
+	atomicLevel.SetLevel(config.Level.Level())
+	config.Level = atomicLevel
+
 	// Build the logger
 	z, err := config.Build(options...)
 	if err != nil {
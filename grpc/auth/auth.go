@@ -1,5 +1,7 @@
 package auth
 
+import "sync/atomic"
+
 const (
 	DefaultHeaderName = "Authorization"
 	DefaultScheme     = "Bearer"
@@ -42,11 +44,55 @@ func WithSimpleAuth(isEnable bool, keys ...string) ConfigOption {
 	}
 }
 
+// WithKeyStore verifies API keys against store instead of the static keys, so that they can be refreshed at runtime
+func WithKeyStore(store *KeyStore) ConfigOption {
+	return func(c *Config) {
+		c.KeyStore = store
+	}
+}
+
 // Config holds the authentication configuration settings
 type Config struct {
 	Enabled     bool
 	HeaderName  string
 	Scheme      string
 	Keys        map[string]bool // For static API key verification; supports multiple keys
+	KeyStore    *KeyStore       // Keys that can be replaced at runtime; when set, Keys is ignored
 	SkipMethods map[string]bool // Methods to skip authentication verification for
 }
+
+// HasKey reports whether key is an allowed API key
+func (c *Config) HasKey(key string) bool {
+	if c.KeyStore != nil {
+		return c.KeyStore.Has(key)
+	}
+	return c.Keys[key]
+}
+
+// KeyStore holds API keys that can be replaced while requests are verified, e.g. by a reload hook after a
+// sidecar rotated the secrets
+type KeyStore struct {
+	keys atomic.Pointer[map[string]bool]
+}
+
+// NewKeyStore returns a KeyStore allowing keys
+func NewKeyStore(keys ...string) *KeyStore {
+	store := &KeyStore{}
+	store.Set(keys...)
+	return store
+}
+
+// Set replaces the allowed keys
+func (s *KeyStore) Set(keys ...string) {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+	s.keys.Store(&set)
+}
+
+// Has reports whether key is allowed
+func (s *KeyStore) Has(key string) bool {
+	keys := s.keys.Load()
+	return keys != nil && (*keys)[key]
+}
@@ -0,0 +1,137 @@
+// Package catalog loads declarative error catalogs and generates the matching Go constructors and
+// client documentation, so that internal_error_code values are defined once per service.
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"strconv"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrDuplicateCode = errors.New("duplicate internal error code")
+	ErrDuplicateName = errors.New("duplicate error name")
+	ErrInvalidEntry  = errors.New("invalid catalog entry")
+)
+
+// Catalog is the declarative definition of the errors exposed by a service.
+//
+//	package: walleterrors
+//	errors:
+//	  - name: WalletNotFound
+//	    code: 1001
+//	    grpc_code: NOT_FOUND
+//	    http_status: 404
+//	    message: Wallet not found
+//	    description: The requested wallet does not exist.
+type Catalog struct {
+	Package string  `yaml:"package"` // Go package of the generated constructors
+	Errors  []Entry `yaml:"errors"`
+}
+
+// Entry describes a single error of the catalog.
+type Entry struct {
+	Name        string     `yaml:"name"`        // Exported Go identifier, also used as Private.ErrorType
+	Code        int32      `yaml:"code"`        // Public.InternalErrorCode, unique in the catalog
+	GRPCCode    codes.Code `yaml:"grpc_code"`   // gRPC code name, e.g. NOT_FOUND
+	HTTPStatus  int        `yaml:"http_status"` // Defaults to the grpc-gateway mapping of GRPCCode
+	Message     string     `yaml:"message"`     // Default client-safe Public.CustomMessage
+	Description string     `yaml:"description"`
+}
+
+// entryYAML mirrors Entry with the gRPC code as a string, codes.Code only unmarshals from JSON.
+type entryYAML struct {
+	Name        string `yaml:"name"`
+	Code        int32  `yaml:"code"`
+	GRPCCode    string `yaml:"grpc_code"`
+	HTTPStatus  int    `yaml:"http_status"`
+	Message     string `yaml:"message"`
+	Description string `yaml:"description"`
+}
+
+// UnmarshalYAML decodes an entry, parsing the gRPC code name.
+func (e *Entry) UnmarshalYAML(node *yaml.Node) error {
+	var raw entryYAML
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(strconv.Quote(raw.GRPCCode))); err != nil {
+		return fmt.Errorf("%w: %s: grpc_code %q: %w", ErrInvalidEntry, raw.Name, raw.GRPCCode, err)
+	}
+	*e = Entry{
+		Name:        raw.Name,
+		Code:        raw.Code,
+		GRPCCode:    code,
+		HTTPStatus:  raw.HTTPStatus,
+		Message:     raw.Message,
+		Description: raw.Description,
+	}
+	return nil
+}
+
+// Load reads and validates the catalog at path. JSON catalogs are accepted too, as JSON is valid YAML.
+func Load(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a YAML catalog. Missing HTTP statuses are filled from the gRPC code.
+func Parse(data []byte) (*Catalog, error) {
+	var c Catalog
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse catalog: %w", err)
+	}
+	for i := range c.Errors {
+		if c.Errors[i].HTTPStatus == 0 {
+			c.Errors[i].HTTPStatus = runtime.HTTPStatusFromCode(c.Errors[i].GRPCCode)
+		}
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Validate checks that names are exported Go identifiers, that codes are positive and that names and codes
+// are unique.
+func (c *Catalog) Validate() error {
+	if !token.IsIdentifier(c.Package) {
+		return fmt.Errorf("%w: package %q is not a valid Go identifier", ErrInvalidEntry, c.Package)
+	}
+
+	names := make(map[string]struct{}, len(c.Errors))
+	codesByValue := make(map[int32]string, len(c.Errors))
+	for _, e := range c.Errors {
+		if !token.IsIdentifier(e.Name) || !token.IsExported(e.Name) {
+			return fmt.Errorf("%w: name %q is not an exported Go identifier", ErrInvalidEntry, e.Name)
+		}
+		if e.Code <= 0 {
+			return fmt.Errorf("%w: %s: code must be positive", ErrInvalidEntry, e.Name)
+		}
+		if e.GRPCCode == codes.OK {
+			return fmt.Errorf("%w: %s: grpc_code must not be OK", ErrInvalidEntry, e.Name)
+		}
+		if e.Message == "" {
+			return fmt.Errorf("%w: %s: message is required", ErrInvalidEntry, e.Name)
+		}
+		if _, ok := names[e.Name]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicateName, e.Name)
+		}
+		names[e.Name] = struct{}{}
+		if other, ok := codesByValue[e.Code]; ok {
+			return fmt.Errorf("%w: %d used by %s and %s", ErrDuplicateCode, e.Code, other, e.Name)
+		}
+		codesByValue[e.Code] = e.Name
+	}
+	return nil
+}
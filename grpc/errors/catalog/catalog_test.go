@@ -0,0 +1,93 @@
+package catalog_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/rainbow-me/platform-tools/grpc/errors/catalog"
+)
+
+const walletCatalog = `
+package: walleterrors
+errors:
+  - name: WalletNotFound
+    code: 1001
+    grpc_code: NOT_FOUND
+    message: Wallet not found
+    description: The requested wallet does not exist.
+  - name: WalletLocked
+    code: 1002
+    grpc_code: FAILED_PRECONDITION
+    http_status: 423
+    message: Wallet is locked
+`
+
+func TestParse(t *testing.T) {
+	c, err := catalog.Parse([]byte(walletCatalog))
+	require.NoError(t, err)
+	require.Len(t, c.Errors, 2)
+	require.Equal(t, codes.NotFound, c.Errors[0].GRPCCode)
+	require.Equal(t, 404, c.Errors[0].HTTPStatus)
+	require.Equal(t, 423, c.Errors[1].HTTPStatus)
+
+	src, err := c.GenerateGo()
+	require.NoError(t, err)
+	require.Contains(t, string(src), "CodeWalletNotFound int32 = 1001")
+	require.Contains(t, string(src), "func NewWalletLocked(message string, opts ...grpcerrors.ServiceErrorOption) error {")
+	require.Contains(t, string(src), "grpcerrors.NewServiceError(codes.FailedPrecondition, message, opts...)")
+	require.Contains(t, string(src), "var HTTPStatuses = map[int32]int{\n\tCodeWalletLocked: 423,\n}")
+
+	md := c.GenerateMarkdown()
+	require.Contains(t, string(md), "| 1001 | WalletNotFound | NOT_FOUND | 404 | Wallet not found | The requested wallet does not exist. |")
+
+	out, err := c.GenerateJSON()
+	require.NoError(t, err)
+	require.Contains(t, string(out), `"grpc_code": "FAILED_PRECONDITION"`)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		catalog string
+		wantErr error
+	}{
+		{
+			name: "duplicate code",
+			catalog: `
+package: walleterrors
+errors:
+  - {name: WalletNotFound, code: 1001, grpc_code: NOT_FOUND, message: Wallet not found}
+  - {name: WalletLocked, code: 1001, grpc_code: FAILED_PRECONDITION, message: Wallet is locked}
+`,
+			wantErr: catalog.ErrDuplicateCode,
+		},
+		{
+			name: "duplicate name",
+			catalog: `
+package: walleterrors
+errors:
+  - {name: WalletNotFound, code: 1001, grpc_code: NOT_FOUND, message: Wallet not found}
+  - {name: WalletNotFound, code: 1002, grpc_code: NOT_FOUND, message: Wallet not found}
+`,
+			wantErr: catalog.ErrDuplicateName,
+		},
+		{
+			name: "unknown grpc code",
+			catalog: `
+package: walleterrors
+errors:
+  - {name: WalletNotFound, code: 1001, grpc_code: MISSING, message: Wallet not found}
+`,
+			wantErr: catalog.ErrInvalidEntry,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := catalog.Parse([]byte(tt.catalog))
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
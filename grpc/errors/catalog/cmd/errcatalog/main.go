@@ -0,0 +1,76 @@
+// Command errcatalog generates Go error constructors and client documentation from an error catalog.
+//
+// Usage:
+//
+//	errcatalog -in errors.yaml -go errors_gen.go [-md ERRORS.md] [-json errors.json]
+//
+// Typically invoked with go:generate:
+//
+//	//go:generate go run github.com/rainbow-me/platform-tools/grpc/errors/catalog/cmd/errcatalog -in errors.yaml -go errors_gen.go -md ERRORS.md
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rainbow-me/platform-tools/grpc/errors/catalog"
+)
+
+func main() {
+	in := flag.String("in", "", "path of the YAML (or JSON) error catalog")
+	goOut := flag.String("go", "", "output path of the generated Go constructors")
+	mdOut := flag.String("md", "", "output path of the Markdown catalog (optional)")
+	jsonOut := flag.String("json", "", "output path of the JSON catalog (optional)")
+	flag.Parse()
+
+	if err := run(*in, *goOut, *mdOut, *jsonOut); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "errcatalog: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, goOut, mdOut, jsonOut string) error {
+	if in == "" || goOut == "" {
+		flag.Usage()
+		return fmt.Errorf("-in and -go are required")
+	}
+
+	c, err := catalog.Load(in)
+	if err != nil {
+		return err
+	}
+
+	src, err := c.GenerateGo()
+	if err != nil {
+		return err
+	}
+	if err = writeFile(goOut, src); err != nil {
+		return err
+	}
+
+	if mdOut != "" {
+		if err = writeFile(mdOut, c.GenerateMarkdown()); err != nil {
+			return err
+		}
+	}
+
+	if jsonOut != "" {
+		out, jsonErr := c.GenerateJSON()
+		if jsonErr != nil {
+			return jsonErr
+		}
+		if err = writeFile(jsonOut, out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeFile(path string, data []byte) error {
+	if err := os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec // generated files are committed to the repository
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/code"
+)
+
+var goTemplate = template.Must(template.New("go").Funcs(template.FuncMap{
+	"comment":            comment,
+	"customHTTPStatuses": customHTTPStatuses,
+}).Parse(`// Code generated by errcatalog. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"google.golang.org/grpc/codes"
+
+	grpcerrors "github.com/rainbow-me/platform-tools/grpc/errors"
+)
+
+// Internal error codes, returned to clients as Public.InternalErrorCode.
+const (
+{{- range .Errors }}
+	Code{{ .Name }} int32 = {{ .Code }}
+{{- end }}
+)
+
+// HTTPStatuses maps the internal error codes with a non-default HTTP status to it, for the REST gateway:
+// gateway.WithErrorCodeHTTPStatus(HTTPStatuses).
+var HTTPStatuses = map[int32]int{
+{{- range customHTTPStatuses .Errors }}
+	Code{{ .Name }}: {{ .HTTPStatus }},
+{{- end }}
+}
+{{ range .Errors }}
+// New{{ .Name }} returns a {{ .GRPCCode }} error with internal error code {{ .Code }}.
+{{- if .Description }}
+// {{ comment .Description }}
+{{- end }}
+// The message is private, the client receives {{ printf "%q" (comment .Message) }} unless overridden with WithClientProps.
+func New{{ .Name }}(message string, opts ...grpcerrors.ServiceErrorOption) error {
+	opts = append([]grpcerrors.ServiceErrorOption{
+		grpcerrors.WithType({{ printf "%q" .Name }}),
+		grpcerrors.WithClientProps(Code{{ .Name }}, {{ printf "%q" .Message }}, nil),
+	}, opts...)
+	return grpcerrors.NewServiceError(codes.{{ .GRPCCode }}, message, opts...)
+}
+{{ end }}`))
+
+// customHTTPStatuses returns the entries whose HTTP status differs from the default mapping of their gRPC code.
+func customHTTPStatuses(entries []Entry) []Entry {
+	var custom []Entry
+	for _, e := range entries {
+		if e.HTTPStatus != runtime.HTTPStatusFromCode(e.GRPCCode) {
+			custom = append(custom, e)
+		}
+	}
+	return custom
+}
+
+// GenerateGo returns the gofmt-ed Go source of the catalog constructors.
+func (c *Catalog) GenerateGo() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := goTemplate.Execute(&buf, c); err != nil {
+		return nil, fmt.Errorf("failed to render Go source: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format Go source: %w", err)
+	}
+	return src, nil
+}
+
+// documentedEntry is the client-facing representation of an Entry in the JSON and Markdown catalogs.
+type documentedEntry struct {
+	Name        string `json:"name"`
+	Code        int32  `json:"code"`
+	GRPCCode    string `json:"grpc_code"`
+	HTTPStatus  int    `json:"http_status"`
+	Message     string `json:"message"`
+	Description string `json:"description,omitempty"`
+}
+
+func (c *Catalog) documentedEntries() []documentedEntry {
+	entries := make([]documentedEntry, 0, len(c.Errors))
+	for _, e := range c.Errors {
+		entries = append(entries, documentedEntry{
+			Name:        e.Name,
+			Code:        e.Code,
+			GRPCCode:    code.Code(e.GRPCCode).String(), //nolint:gosec // gRPC codes are small positive integers
+			HTTPStatus:  e.HTTPStatus,
+			Message:     e.Message,
+			Description: e.Description,
+		})
+	}
+	return entries
+}
+
+// GenerateJSON returns the catalog as a JSON array for client teams.
+func (c *Catalog) GenerateJSON() ([]byte, error) {
+	out, err := json.MarshalIndent(c.documentedEntries(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render JSON catalog: %w", err)
+	}
+	return append(out, '\n'), nil
+}
+
+// GenerateMarkdown returns the catalog as a Markdown table for client teams.
+func (c *Catalog) GenerateMarkdown() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Error Catalog\n\n")
+	buf.WriteString("| Code | Name | gRPC code | HTTP status | Message | Description |\n")
+	buf.WriteString("|------|------|-----------|-------------|---------|-------------|\n")
+	for _, e := range c.documentedEntries() {
+		fmt.Fprintf(&buf, "| %d | %s | %s | %d | %s | %s |\n",
+			e.Code, e.Name, e.GRPCCode, e.HTTPStatus, markdownCell(e.Message), markdownCell(e.Description))
+	}
+	return buf.Bytes()
+}
+
+// comment flattens text so it fits on a single Go comment line.
+func comment(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// markdownCell escapes text for use in a Markdown table cell.
+func markdownCell(text string) string {
+	return strings.ReplaceAll(comment(text), "|", `\|`)
+}
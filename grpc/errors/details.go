@@ -0,0 +1,174 @@
+package errors
+
+import (
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// WithStatusDetails attaches arbitrary proto messages to the gRPC status, next to the BackendServiceError.
+// Prefer the typed helpers below for the standard google.rpc error details.
+func WithStatusDetails(details ...proto.Message) ServiceErrorOption {
+	return func(detail *ServiceErrorWrapper) {
+		detail.StatusDetails = append(detail.StatusDetails, details...)
+	}
+}
+
+// WithErrorInfo attaches a google.rpc.ErrorInfo describing the cause of the error.
+// Reason should be a constant UPPER_SNAKE_CASE identifier and domain the logical service owning it.
+func WithErrorInfo(reason, domain string, metadata map[string]string) ServiceErrorOption {
+	return WithStatusDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   domain,
+		Metadata: metadata,
+	})
+}
+
+// FieldViolation builds a google.rpc.BadRequest field violation for use with WithBadRequest.
+func FieldViolation(field, description string) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: description,
+	}
+}
+
+// WithBadRequest attaches google.rpc.BadRequest field violations.
+// Calling it multiple times merges all violations into a single BadRequest detail.
+func WithBadRequest(violations ...*errdetails.BadRequest_FieldViolation) ServiceErrorOption {
+	return func(detail *ServiceErrorWrapper) {
+		badRequest := findOrAppendDetail(detail, &errdetails.BadRequest{})
+		badRequest.FieldViolations = append(badRequest.FieldViolations, violations...)
+	}
+}
+
+// WithRetryInfo attaches a google.rpc.RetryInfo telling clients how long to wait before retrying.
+func WithRetryInfo(delay time.Duration) ServiceErrorOption {
+	return WithStatusDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(delay),
+	})
+}
+
+// QuotaViolation builds a google.rpc.QuotaFailure violation for use with WithQuotaFailure.
+func QuotaViolation(subject, description string) *errdetails.QuotaFailure_Violation {
+	return &errdetails.QuotaFailure_Violation{
+		Subject:     subject,
+		Description: description,
+	}
+}
+
+// WithQuotaFailure attaches google.rpc.QuotaFailure violations.
+// Calling it multiple times merges all violations into a single QuotaFailure detail.
+func WithQuotaFailure(violations ...*errdetails.QuotaFailure_Violation) ServiceErrorOption {
+	return func(detail *ServiceErrorWrapper) {
+		quotaFailure := findOrAppendDetail(detail, &errdetails.QuotaFailure{})
+		quotaFailure.Violations = append(quotaFailure.Violations, violations...)
+	}
+}
+
+// PreconditionViolation builds a google.rpc.PreconditionFailure violation for use with WithPreconditionFailure.
+func PreconditionViolation(violationType, subject, description string) *errdetails.PreconditionFailure_Violation {
+	return &errdetails.PreconditionFailure_Violation{
+		Type:        violationType,
+		Subject:     subject,
+		Description: description,
+	}
+}
+
+// WithPreconditionFailure attaches google.rpc.PreconditionFailure violations.
+// Calling it multiple times merges all violations into a single PreconditionFailure detail.
+func WithPreconditionFailure(violations ...*errdetails.PreconditionFailure_Violation) ServiceErrorOption {
+	return func(detail *ServiceErrorWrapper) {
+		preconditionFailure := findOrAppendDetail(detail, &errdetails.PreconditionFailure{})
+		preconditionFailure.Violations = append(preconditionFailure.Violations, violations...)
+	}
+}
+
+// WithResourceInfo attaches a google.rpc.ResourceInfo describing the resource being accessed.
+func WithResourceInfo(resourceType, resourceName, owner, description string) ServiceErrorOption {
+	return WithStatusDetails(&errdetails.ResourceInfo{
+		ResourceType: resourceType,
+		ResourceName: resourceName,
+		Owner:        owner,
+		Description:  description,
+	})
+}
+
+// WithLocalizedMessage attaches a google.rpc.LocalizedMessage, e.g. ("en-US", "Wallet not found").
+func WithLocalizedMessage(locale, message string) ServiceErrorOption {
+	return WithStatusDetails(&errdetails.LocalizedMessage{
+		Locale:  locale,
+		Message: message,
+	})
+}
+
+// ParseErrorInfo returns the google.rpc.ErrorInfo attached to err, if any.
+func ParseErrorInfo(err error) (*errdetails.ErrorInfo, bool) {
+	return parseStatusDetail[*errdetails.ErrorInfo](err)
+}
+
+// ParseBadRequest returns the google.rpc.BadRequest attached to err, if any.
+func ParseBadRequest(err error) (*errdetails.BadRequest, bool) {
+	return parseStatusDetail[*errdetails.BadRequest](err)
+}
+
+// ParseRetryInfo returns the google.rpc.RetryInfo attached to err, if any.
+func ParseRetryInfo(err error) (*errdetails.RetryInfo, bool) {
+	return parseStatusDetail[*errdetails.RetryInfo](err)
+}
+
+// ParseQuotaFailure returns the google.rpc.QuotaFailure attached to err, if any.
+func ParseQuotaFailure(err error) (*errdetails.QuotaFailure, bool) {
+	return parseStatusDetail[*errdetails.QuotaFailure](err)
+}
+
+// ParsePreconditionFailure returns the google.rpc.PreconditionFailure attached to err, if any.
+func ParsePreconditionFailure(err error) (*errdetails.PreconditionFailure, bool) {
+	return parseStatusDetail[*errdetails.PreconditionFailure](err)
+}
+
+// ParseResourceInfo returns the google.rpc.ResourceInfo attached to err, if any.
+func ParseResourceInfo(err error) (*errdetails.ResourceInfo, bool) {
+	return parseStatusDetail[*errdetails.ResourceInfo](err)
+}
+
+// ParseLocalizedMessage returns the google.rpc.LocalizedMessage attached to err, if any.
+func ParseLocalizedMessage(err error) (*errdetails.LocalizedMessage, bool) {
+	return parseStatusDetail[*errdetails.LocalizedMessage](err)
+}
+
+// parseStatusDetail returns the first status detail of type T carried by err.
+// Works with any error convertible to a gRPC status, not only those built by NewServiceError.
+func parseStatusDetail[T proto.Message](err error) (T, bool) {
+	var zero T
+	if err == nil {
+		return zero, false
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return zero, false
+	}
+
+	for _, detail := range st.Details() {
+		if typed, isType := detail.(T); isType {
+			return typed, true
+		}
+	}
+
+	return zero, false
+}
+
+// findOrAppendDetail returns the status detail of type T already added to the wrapper,
+// or appends and returns empty when none exists yet.
+func findOrAppendDetail[T proto.Message](detail *ServiceErrorWrapper, empty T) T {
+	for _, existing := range detail.StatusDetails {
+		if typed, ok := existing.(T); ok {
+			return typed
+		}
+	}
+	detail.StatusDetails = append(detail.StatusDetails, empty)
+	return empty
+}
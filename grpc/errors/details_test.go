@@ -0,0 +1,73 @@
+package errors_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	grpcerrors "github.com/rainbow-me/platform-tools/grpc/errors"
+)
+
+func TestStandardDetails(t *testing.T) {
+	err := grpcerrors.NewServiceError(codes.InvalidArgument, "invalid transfer",
+		grpcerrors.WithClientProps(2001, "Invalid transfer", nil),
+		grpcerrors.WithErrorInfo("INVALID_AMOUNT", "wallet.rainbow.me", map[string]string{"currency": "ETH"}),
+		grpcerrors.WithBadRequest(grpcerrors.FieldViolation("amount", "must be positive")),
+		grpcerrors.WithBadRequest(grpcerrors.FieldViolation("to", "invalid address")),
+		grpcerrors.WithRetryInfo(3*time.Second),
+		grpcerrors.WithQuotaFailure(grpcerrors.QuotaViolation("user:1", "daily limit")),
+		grpcerrors.WithPreconditionFailure(grpcerrors.PreconditionViolation("TOS", "user:1", "terms not accepted")),
+		grpcerrors.WithResourceInfo("wallet", "0xabc", "user:1", "source wallet"),
+		grpcerrors.WithLocalizedMessage("en-US", "The amount must be positive"),
+	)
+
+	// BackendServiceError + 7 standard details, with both BadRequest options merged
+	require.Len(t, status.Convert(err).Details(), 8)
+
+	backendErr, parseErr := grpcerrors.ParseBackendServiceError(err)
+	require.NoError(t, parseErr)
+	require.Equal(t, int32(2001), backendErr.GetPublic().GetInternalErrorCode())
+
+	errInfo, ok := grpcerrors.ParseErrorInfo(err)
+	require.True(t, ok)
+	require.Equal(t, "INVALID_AMOUNT", errInfo.GetReason())
+	require.Equal(t, "ETH", errInfo.GetMetadata()["currency"])
+
+	badRequest, ok := grpcerrors.ParseBadRequest(err)
+	require.True(t, ok)
+	require.Len(t, badRequest.GetFieldViolations(), 2)
+
+	retryInfo, ok := grpcerrors.ParseRetryInfo(err)
+	require.True(t, ok)
+	require.Equal(t, 3*time.Second, retryInfo.GetRetryDelay().AsDuration())
+
+	quotaFailure, ok := grpcerrors.ParseQuotaFailure(err)
+	require.True(t, ok)
+	require.Equal(t, "user:1", quotaFailure.GetViolations()[0].GetSubject())
+
+	preconditionFailure, ok := grpcerrors.ParsePreconditionFailure(err)
+	require.True(t, ok)
+	require.Equal(t, "TOS", preconditionFailure.GetViolations()[0].GetType())
+
+	resourceInfo, ok := grpcerrors.ParseResourceInfo(err)
+	require.True(t, ok)
+	require.Equal(t, "0xabc", resourceInfo.GetResourceName())
+
+	localized, ok := grpcerrors.ParseLocalizedMessage(err)
+	require.True(t, ok)
+	require.Equal(t, "en-US", localized.GetLocale())
+}
+
+func TestStandardDetails_Missing(t *testing.T) {
+	_, ok := grpcerrors.ParseErrorInfo(nil)
+	require.False(t, ok)
+
+	_, ok = grpcerrors.ParseErrorInfo(status.Error(codes.Internal, "no details"))
+	require.False(t, ok)
+
+	_, ok = grpcerrors.ParseRetryInfo(grpcerrors.NewServiceError(codes.Internal, "backend only"))
+	require.False(t, ok)
+}
@@ -0,0 +1,259 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	errorpb "github.com/rainbow-me/platform-tools/grpc/protos/gen/go/error"
+)
+
+// Metadata keys recording the cause chain of a wrapped downstream error in Private.Metadata.
+// Keys are indexed, cause.0 being the service called directly and cause.N the Nth hop further down,
+// e.g. "cause.0.service", "cause.0.method", "cause.1.service".
+const (
+	causeKeyPrefix            = "cause."
+	CauseServiceKey           = "service"
+	CauseMethodKey            = "method"
+	CauseGRPCCodeKey          = "grpc_code"
+	CauseInternalErrorCodeKey = "internal_error_code"
+	CauseMessageKey           = "message"
+)
+
+// DownstreamCall identifies the downstream service call that returned an error.
+type DownstreamCall struct {
+	Service string // Name of the called service, e.g. "wallet-service"
+	Method  string // Full gRPC method, e.g. "/wallet.v1.WalletService/GetWallet"
+}
+
+// DownstreamAction describes how an error returned by a downstream service is surfaced to our own caller.
+type DownstreamAction int
+
+const (
+	// DownstreamPropagate returns the downstream error unchanged: same code, message and details.
+	DownstreamPropagate DownstreamAction = iota
+	// DownstreamRecode keeps the downstream BackendServiceError but changes the gRPC code.
+	DownstreamRecode
+	// DownstreamWrap creates a new error recording the downstream error as cause in Private.Metadata.
+	DownstreamWrap
+)
+
+// DownstreamDecision is returned by a DownstreamPolicy.
+type DownstreamDecision struct {
+	Action  DownstreamAction
+	Code    codes.Code           // Code used by DownstreamRecode and DownstreamWrap
+	Message string               // Private message used by DownstreamWrap; defaults to "<service> <method> failed"
+	Options []ServiceErrorOption // Additional options applied by DownstreamRecode and DownstreamWrap
+}
+
+// DownstreamPolicy decides how a downstream error is surfaced, given its status and the parsed
+// BackendServiceError (nil when the downstream did not send one).
+type DownstreamPolicy func(st *status.Status, backendErr *errorpb.BackendServiceError) DownstreamDecision
+
+// DefaultDownstreamPolicy propagates errors caused by the client request unchanged, so that their
+// internal_error_code and custom_message reach the end user, and wraps everything else.
+// Authentication and permission failures between services are not the caller's fault and become Internal.
+func DefaultDownstreamPolicy(st *status.Status, _ *errorpb.BackendServiceError) DownstreamDecision {
+	switch st.Code() { //nolint:exhaustive // all other codes are wrapped
+	case codes.InvalidArgument,
+		codes.NotFound,
+		codes.AlreadyExists,
+		codes.FailedPrecondition,
+		codes.OutOfRange,
+		codes.Aborted:
+		return DownstreamDecision{Action: DownstreamPropagate}
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return DownstreamDecision{Action: DownstreamWrap, Code: codes.Internal}
+	default:
+		return DownstreamDecision{Action: DownstreamWrap, Code: st.Code()}
+	}
+}
+
+// HandleDownstreamError applies policy to an error returned by a downstream gRPC call.
+// Errors that are not gRPC status errors (e.g. local failures) are returned unchanged.
+// A nil policy uses DefaultDownstreamPolicy.
+func HandleDownstreamError(err error, call DownstreamCall, policy DownstreamPolicy) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	if policy == nil {
+		policy = DefaultDownstreamPolicy
+	}
+
+	backendErr, _ := extractBackendError(st.Proto())
+	decision := policy(st, backendErr)
+
+	switch decision.Action {
+	case DownstreamRecode:
+		return RecodeDownstreamError(err, decision.Code, decision.Options...)
+	case DownstreamWrap:
+		return WrapDownstreamError(err, decision.Code, decision.Message, call, decision.Options...)
+	default:
+		return PropagateDownstreamError(err)
+	}
+}
+
+// PropagateDownstreamError returns the downstream status error unchanged, even when err has been wrapped
+// locally (e.g. with fmt.Errorf), so that code, message and details reach our own caller as sent.
+func PropagateDownstreamError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var grpcStatus interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &grpcStatus) {
+		return err
+	}
+	return grpcStatus.GRPCStatus().Err()
+}
+
+// RecodeDownstreamError returns a new status error with the given code, keeping the downstream
+// BackendServiceError (public code, custom message and details). Options are applied on top of it.
+// Non-status errors are returned unchanged.
+func RecodeDownstreamError(err error, code codes.Code, opts ...ServiceErrorOption) error {
+	st, ok := status.FromError(PropagateDownstreamError(err))
+	if !ok {
+		return err
+	}
+
+	// keep the other status details, e.g. google.rpc.ErrorInfo
+	var statusDetails []proto.Message
+	for _, detail := range st.Details() {
+		if msg, isProto := detail.(proto.Message); isProto {
+			if _, isBackendErr := msg.(*errorpb.BackendServiceError); !isBackendErr {
+				statusDetails = append(statusDetails, msg)
+			}
+		}
+	}
+
+	backendErr, parseErr := extractBackendError(st.Proto())
+	if parseErr != nil {
+		opts = append([]ServiceErrorOption{WithStatusDetails(statusDetails...)}, opts...)
+		return NewServiceError(code, st.Message(), opts...)
+	}
+
+	return newServiceErrorFrom(code, backendErr, statusDetails, opts...)
+}
+
+// WrapDownstreamError returns a new status error with the given code whose BackendServiceError records the
+// downstream error as cause: the called service, method, code, internal error code and message are stored in
+// Private.Metadata, shifting any cause chain sent by the downstream service one level down. The downstream
+// public section is kept unless overridden with WithClientProps. Non-status errors are returned unchanged.
+func WrapDownstreamError(
+	err error,
+	code codes.Code,
+	message string,
+	call DownstreamCall,
+	opts ...ServiceErrorOption,
+) error {
+	st, ok := status.FromError(PropagateDownstreamError(err))
+	if !ok {
+		return err
+	}
+	if message == "" {
+		message = strings.TrimSpace(fmt.Sprintf("%s %s failed", call.Service, call.Method))
+	}
+
+	public := &errorpb.BackendServiceError_Public{}
+	metadata := make(map[string]string)
+
+	downstream, parseErr := extractBackendError(st.Proto())
+	if parseErr == nil {
+		if downstream.GetPublic() != nil {
+			public = downstream.GetPublic()
+		}
+		// shift the downstream cause chain one level down
+		for key, value := range downstream.GetPrivate().GetMetadata() {
+			if idx, rest, isCause := parseCauseKey(key); isCause {
+				metadata[causeKey(idx+1, rest)] = value
+			}
+		}
+	}
+
+	metadata[causeKey(0, CauseServiceKey)] = call.Service
+	metadata[causeKey(0, CauseMethodKey)] = call.Method
+	metadata[causeKey(0, CauseGRPCCodeKey)] = st.Code().String()
+	metadata[causeKey(0, CauseMessageKey)] = st.Message()
+	if downstream != nil {
+		metadata[causeKey(0, CauseInternalErrorCodeKey)] =
+			strconv.FormatInt(int64(downstream.GetPublic().GetInternalErrorCode()), 10)
+	}
+
+	wrapped := &errorpb.BackendServiceError{
+		Public: public,
+		Private: &errorpb.BackendServiceError_Private{
+			Message:  message,
+			RawError: FormatRawError(err),
+			Metadata: metadata,
+		},
+	}
+
+	return newServiceErrorFrom(code, wrapped, nil, opts...)
+}
+
+// DownstreamCauses returns the cause chain recorded by WrapDownstreamError, cause 0 first.
+func DownstreamCauses(backendErr *errorpb.BackendServiceError) []map[string]string {
+	var causes []map[string]string
+	for key, value := range backendErr.GetPrivate().GetMetadata() {
+		idx, rest, ok := parseCauseKey(key)
+		if !ok {
+			continue
+		}
+		for len(causes) <= idx {
+			causes = append(causes, make(map[string]string))
+		}
+		causes[idx][rest] = value
+	}
+	return causes
+}
+
+func causeKey(idx int, field string) string {
+	return causeKeyPrefix + strconv.Itoa(idx) + "." + field
+}
+
+// parseCauseKey parses "cause.<idx>.<field>" metadata keys.
+func parseCauseKey(key string) (int, string, bool) {
+	rest, ok := strings.CutPrefix(key, causeKeyPrefix)
+	if !ok {
+		return 0, "", false
+	}
+	idxStr, field, ok := strings.Cut(rest, ".")
+	if !ok {
+		return 0, "", false
+	}
+	idx, err := strconv.Atoi(idxStr)
+	if err != nil || idx < 0 {
+		return 0, "", false
+	}
+	return idx, field, true
+}
+
+// newServiceErrorFrom builds a status error from an existing BackendServiceError, applying opts on top.
+// The detail is owned by the caller: it must not be shared with other errors, as opts mutate it.
+func newServiceErrorFrom(
+	code codes.Code,
+	detail *errorpb.BackendServiceError,
+	statusDetails []proto.Message,
+	opts ...ServiceErrorOption,
+) error {
+	if detail.GetPublic() == nil {
+		detail.Public = &errorpb.BackendServiceError_Public{}
+	}
+	if detail.GetPrivate() == nil {
+		detail.Private = &errorpb.BackendServiceError_Private{}
+	}
+
+	wrapper := &ServiceErrorWrapper{Detail: detail, StatusDetails: statusDetails}
+	for i := range opts {
+		opts[i](wrapper)
+	}
+	return wrapper.toStatusError(code)
+}
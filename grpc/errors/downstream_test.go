@@ -0,0 +1,98 @@
+package errors_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rainbow-me/platform-tools/common/env"
+	grpcerrors "github.com/rainbow-me/platform-tools/grpc/errors"
+)
+
+func TestHandleDownstreamError(t *testing.T) {
+	notFound := grpcerrors.NewServiceError(codes.NotFound, "wallet missing",
+		grpcerrors.WithClientProps(1001, "Wallet not found", nil),
+		grpcerrors.WithErrorInfo("WALLET_NOT_FOUND", "wallet.rainbow.me", nil),
+	)
+	unavailable := grpcerrors.NewServiceError(codes.Unavailable, "db down",
+		grpcerrors.WithClientProps(5001, "Try again later", nil),
+		grpcerrors.WithMetadata(map[string]string{"cause.0.service": "db-proxy", "cause.0.method": "/db.Proxy/Query"}),
+	)
+	call := grpcerrors.DownstreamCall{Service: "wallet-service", Method: "/wallet.v1.WalletService/GetWallet"}
+
+	t.Run("propagate locally wrapped error", func(t *testing.T) {
+		err := grpcerrors.HandleDownstreamError(fmt.Errorf("get wallet: %w", notFound), call, nil)
+		st := status.Convert(err)
+		require.Equal(t, codes.NotFound, st.Code())
+		require.Equal(t, "wallet missing", st.Message())
+
+		backendErr, parseErr := grpcerrors.ParseBackendServiceError(err)
+		require.NoError(t, parseErr)
+		require.Equal(t, int32(1001), backendErr.GetPublic().GetInternalErrorCode())
+		_, ok := grpcerrors.ParseErrorInfo(err)
+		require.True(t, ok)
+	})
+
+	t.Run("recode keeps public details", func(t *testing.T) {
+		err := grpcerrors.RecodeDownstreamError(notFound, codes.FailedPrecondition)
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		backendErr, parseErr := grpcerrors.ParseBackendServiceError(err)
+		require.NoError(t, parseErr)
+		require.Equal(t, int32(1001), backendErr.GetPublic().GetInternalErrorCode())
+		require.Equal(t, "Wallet not found", backendErr.GetPublic().GetCustomMessage())
+		_, ok := grpcerrors.ParseErrorInfo(err)
+		require.True(t, ok)
+	})
+
+	t.Run("wrap records cause chain", func(t *testing.T) {
+		err := grpcerrors.HandleDownstreamError(unavailable, call, nil)
+		require.Equal(t, codes.Unavailable, status.Code(err))
+
+		backendErr, parseErr := grpcerrors.ParseBackendServiceError(err)
+		require.NoError(t, parseErr)
+		require.Equal(t, int32(5001), backendErr.GetPublic().GetInternalErrorCode())
+		require.Equal(t, "Try again later", backendErr.GetPublic().GetCustomMessage())
+
+		causes := grpcerrors.DownstreamCauses(backendErr)
+		require.Len(t, causes, 2)
+		require.Equal(t, "wallet-service", causes[0][grpcerrors.CauseServiceKey])
+		require.Equal(t, call.Method, causes[0][grpcerrors.CauseMethodKey])
+		require.Equal(t, codes.Unavailable.String(), causes[0][grpcerrors.CauseGRPCCodeKey])
+		require.Equal(t, "5001", causes[0][grpcerrors.CauseInternalErrorCodeKey])
+		require.Equal(t, "db-proxy", causes[1][grpcerrors.CauseServiceKey])
+	})
+
+	t.Run("wrap with overridden client props", func(t *testing.T) {
+		err := grpcerrors.WrapDownstreamError(unavailable, codes.Internal, "", call,
+			grpcerrors.WithClientProps(9000, "Something went wrong", nil),
+		)
+		backendErr, parseErr := grpcerrors.ParseBackendServiceError(err)
+		require.NoError(t, parseErr)
+		require.Equal(t, int32(9000), backendErr.GetPublic().GetInternalErrorCode())
+		require.Equal(t, "wallet-service /wallet.v1.WalletService/GetWallet failed", backendErr.GetPrivate().GetMessage())
+	})
+
+	t.Run("raw error is redacted in production", func(t *testing.T) {
+		t.Setenv(env.ApplicationEnvKey, env.EnvironmentProduction.String())
+		err := grpcerrors.WrapDownstreamError(unavailable, codes.Internal, "", call)
+		backendErr, parseErr := grpcerrors.ParseBackendServiceError(err)
+		require.NoError(t, parseErr)
+		require.Equal(t, grpcerrors.FormatRawError(unavailable), backendErr.GetPrivate().GetRawError())
+		require.NotContains(t, backendErr.GetPrivate().GetRawError(), "db down")
+	})
+
+	t.Run("auth failures become internal", func(t *testing.T) {
+		err := grpcerrors.HandleDownstreamError(status.Error(codes.Unauthenticated, "bad key"), call, nil)
+		require.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("non status error is unchanged", func(t *testing.T) {
+		local := errors.New("local failure")
+		require.Equal(t, local, grpcerrors.HandleDownstreamError(local, call, nil))
+	})
+}
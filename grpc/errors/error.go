@@ -17,24 +17,39 @@ var errInvalidErrorType = errors.New("invalid error type")
 
 type ServiceErrorWrapper struct {
 	Detail *errorpb.BackendServiceError
+
+	// StatusDetails are additional messages (e.g. google.rpc.ErrorInfo) attached to the gRPC status
+	// next to the BackendServiceError. Populated by WithErrorInfo, WithBadRequest and friends.
+	StatusDetails []proto.Message
+
+	// optionErr is the first failure of an option, e.g. marshaling a Public detail, surfaced by NewServiceError
+	// as a codes.Internal error like the failures to marshal the status details.
+	optionErr error
 }
 
 type ServiceErrorOption func(*ServiceErrorWrapper)
 
-// WithOriginalError adds the raw error to the BackendServiceError.
+// WithOriginalError adds the raw error to the BackendServiceError, including its stack trace when the error
+// was created with cockroachdb/errors or pkg/errors. Unsafe details are redacted in production, see FormatRawError.
 func WithOriginalError(err error) ServiceErrorOption {
 	return func(detail *ServiceErrorWrapper) {
 		if err == nil {
 			return
 		}
-		detail.Detail.Private.RawError = err.Error()
+		detail.Detail.Private.RawError = FormatRawError(err)
 	}
 }
 
 // WithMetadata adds metadata to the BackendServiceError.
+// Keys already present are overwritten, other existing keys are kept.
 func WithMetadata(metadata map[string]string) ServiceErrorOption {
 	return func(detail *ServiceErrorWrapper) {
-		detail.Detail.Private.Metadata = metadata
+		if detail.Detail.Private.Metadata == nil {
+			detail.Detail.Private.Metadata = make(map[string]string, len(metadata))
+		}
+		for k, v := range metadata {
+			detail.Detail.Private.Metadata[k] = v
+		}
 	}
 }
 
@@ -82,19 +97,38 @@ func NewServiceError(code codes.Code, message string, opts ...ServiceErrorOption
 		opts[i](backendErr)
 	}
 
-	bytesVal, marshalErr := proto.Marshal(backendErr.Detail)
+	return backendErr.toStatusError(code)
+}
+
+// toStatusError builds the gRPC status error carrying the BackendServiceError and the additional status details.
+func (w *ServiceErrorWrapper) toStatusError(code codes.Code) error {
+	if w.optionErr != nil {
+		return status.Error(codes.Internal, w.optionErr.Error())
+	}
+
+	detail, marshalErr := anypb.New(w.Detail)
 	if marshalErr != nil {
 		return status.Errorf(codes.Internal, "failed to marshal BackendServiceError: %v", marshalErr)
 	}
 
-	err := status.Newf(code, backendErr.Detail.GetPrivate().GetMessage())
+	err := status.Newf(code, w.Detail.GetPrivate().GetMessage())
 	errProto := err.Proto()
-	errProto.Details = append(errProto.GetDetails(), &anypb.Any{Value: bytesVal})
+	errProto.Details = append(errProto.GetDetails(), detail)
+
+	for _, msg := range w.StatusDetails {
+		statusDetail, detailErr := anypb.New(msg)
+		if detailErr != nil {
+			return status.Errorf(codes.Internal, "failed to marshal status detail %T: %v", msg, detailErr)
+		}
+		errProto.Details = append(errProto.Details, statusDetail)
+	}
 
 	return status.ErrorProto(errProto)
 }
 
 // extractBackendError parses a gRPC status and attempts to extract a BackendServiceError from its details.
+// Details are matched on their type URL; details without a type URL are treated as the legacy untyped
+// encoding, which only ever carried a BackendServiceError.
 func extractBackendError(statusProto *googleapistatus.Status) (*errorpb.BackendServiceError, error) {
 	details := statusProto.GetDetails()
 	if len(details) == 0 {
@@ -103,8 +137,17 @@ func extractBackendError(statusProto *googleapistatus.Status) (*errorpb.BackendS
 
 	for _, detail := range details {
 		var backendErr errorpb.BackendServiceError
-		if err := proto.Unmarshal(detail.GetValue(), &backendErr); err != nil {
-			continue // Ignore malformed entries and try the next
+		switch {
+		case detail.GetTypeUrl() == "":
+			if err := proto.Unmarshal(detail.GetValue(), &backendErr); err != nil {
+				continue // Ignore malformed entries and try the next
+			}
+		case detail.MessageIs(&backendErr):
+			if err := detail.UnmarshalTo(&backendErr); err != nil {
+				continue // Ignore malformed entries and try the next
+			}
+		default:
+			continue // Some other detail type, e.g. google.rpc.ErrorInfo
 		}
 		return &backendErr, nil
 	}
@@ -0,0 +1,117 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	grpcerrors "github.com/rainbow-me/platform-tools/grpc/errors"
+	errorpb "github.com/rainbow-me/platform-tools/grpc/protos/gen/go/error"
+)
+
+func TestNewServiceError_TypedDetails(t *testing.T) {
+	err := grpcerrors.NewServiceError(codes.NotFound, "wallet not found",
+		grpcerrors.WithClientProps(1001, "Wallet not found", nil),
+		grpcerrors.WithType("Wallet"),
+	)
+
+	st := status.Convert(err)
+	require.Equal(t, codes.NotFound, st.Code())
+	require.Len(t, st.Proto().GetDetails(), 1)
+	require.Equal(t, "type.googleapis.com/error.BackendServiceError", st.Proto().GetDetails()[0].GetTypeUrl())
+
+	// status.Details resolves the detail through the global registry
+	details := st.Details()
+	require.Len(t, details, 1)
+	backendErr, ok := details[0].(*errorpb.BackendServiceError)
+	require.True(t, ok, "unexpected detail type %T", details[0])
+	require.Equal(t, int32(1001), backendErr.GetPublic().GetInternalErrorCode())
+
+	parsed, err := grpcerrors.ParseBackendServiceError(err)
+	require.NoError(t, err)
+	require.Equal(t, "Wallet", parsed.GetPrivate().GetErrorType())
+	require.Equal(t, "Wallet not found", parsed.GetPublic().GetCustomMessage())
+}
+
+func TestNewServiceError_TranslationKey(t *testing.T) {
+	err := grpcerrors.NewServiceError(codes.NotFound, "wallet not found",
+		grpcerrors.WithTranslationKey("errors.wallet_not_found", map[string]string{"address": "0xabc"}),
+	)
+	backendErr, parseErr := grpcerrors.ParseBackendServiceError(err)
+	require.NoError(t, parseErr)
+	translation, ok := grpcerrors.ParseTranslationKey(backendErr)
+	require.True(t, ok)
+	require.Equal(t, "errors.wallet_not_found", translation.GetKey())
+
+	// A key that cannot be marshaled (invalid UTF-8) fails like the other details instead of being dropped
+	err = grpcerrors.NewServiceError(codes.NotFound, "wallet not found", grpcerrors.WithTranslationKey("\xff", nil))
+	st := status.Convert(err)
+	require.Equal(t, codes.Internal, st.Code())
+	require.Contains(t, st.Message(), "failed to marshal TranslationKey")
+}
+
+func TestParseBackendServiceError(t *testing.T) {
+	backendErr := &errorpb.BackendServiceError{
+		Public:  &errorpb.BackendServiceError_Public{InternalErrorCode: 42},
+		Private: &errorpb.BackendServiceError_Private{Message: "boom"},
+	}
+	raw, err := proto.Marshal(backendErr)
+	require.NoError(t, err)
+
+	errInfo, err := anypb.New(&errdetails.ErrorInfo{Reason: "REASON", Domain: "rainbow.me"})
+	require.NoError(t, err)
+	typed, err := anypb.New(backendErr)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		details  []*anypb.Any
+		wantCode int32
+		wantErr  bool
+	}{
+		{
+			name:    "no details",
+			wantErr: true,
+		},
+		{
+			name:     "legacy detail without type URL",
+			details:  []*anypb.Any{{Value: raw}},
+			wantCode: 42,
+		},
+		{
+			name:     "typed detail",
+			details:  []*anypb.Any{typed},
+			wantCode: 42,
+		},
+		{
+			name:    "other detail type only",
+			details: []*anypb.Any{errInfo},
+			wantErr: true,
+		},
+		{
+			name:     "other detail type before backend error",
+			details:  []*anypb.Any{errInfo, typed},
+			wantCode: 42,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.New(codes.Internal, "boom").Proto()
+			st.Details = tt.details
+
+			parsed, err := grpcerrors.ParseBackendServiceError(status.ErrorProto(st))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCode, parsed.GetPublic().GetInternalErrorCode())
+		})
+	}
+}
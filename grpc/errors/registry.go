@@ -0,0 +1,114 @@
+package errors
+
+import (
+	"errors"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultRegistry is the registry used by the gRPC errors interceptor unless another one is configured.
+// Domain packages typically register their sentinel errors and error types on it during initialization.
+var DefaultRegistry = NewRegistry()
+
+// Mapping describes how a domain error is exposed as a gRPC status error.
+type Mapping struct {
+	Code              codes.Code // gRPC status code returned to the caller
+	InternalErrorCode int32      // Public.InternalErrorCode of the BackendServiceError
+	ErrorType         string     // Private.ErrorType of the BackendServiceError (e.g. "Wallet")
+	Message           string     // Client-safe message; used as status message and Public.CustomMessage
+}
+
+type registryEntry struct {
+	match   func(error) bool
+	mapping Mapping
+}
+
+// Registry maps domain errors to gRPC status errors, so that the domain layer can return plain Go errors
+// and stay framework-agnostic. Errors are matched in registration order, first match wins, so register
+// the most specific errors first. A Registry is safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	entries []registryEntry
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register maps a sentinel error, matched with errors.Is, to the given Mapping.
+func (r *Registry) Register(target error, mapping Mapping) {
+	r.add(func(err error) bool {
+		return errors.Is(err, target)
+	}, mapping)
+}
+
+// RegisterType maps an error type, matched with errors.As, to the given Mapping.
+//
+// Example:
+//
+//	errors.RegisterType[*domain.NotFoundError](errors.DefaultRegistry, errors.Mapping{
+//	    Code:              codes.NotFound,
+//	    InternalErrorCode: 1001,
+//	    ErrorType:         "Wallet",
+//	    Message:           "Wallet not found",
+//	})
+func RegisterType[T error](r *Registry, mapping Mapping) {
+	r.add(func(err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}, mapping)
+}
+
+func (r *Registry) add(match func(error) bool, mapping Mapping) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, registryEntry{match: match, mapping: mapping})
+}
+
+// Lookup returns the Mapping registered for err, if any.
+func (r *Registry) Lookup(err error) (Mapping, bool) {
+	if err == nil {
+		return Mapping{}, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, entry := range r.entries {
+		if entry.match(err) {
+			return entry.mapping, true
+		}
+	}
+	return Mapping{}, false
+}
+
+// Translate converts err into a gRPC status error carrying a BackendServiceError built from its Mapping.
+// The original error is kept in Private.RawError. Errors that are already gRPC status errors, or that
+// have no registered Mapping, are returned unchanged; the boolean reports whether a translation happened.
+func (r *Registry) Translate(err error) (error, bool) {
+	if err == nil {
+		return nil, false
+	}
+	if _, isStatus := status.FromError(err); isStatus {
+		return err, false
+	}
+
+	mapping, ok := r.Lookup(err)
+	if !ok {
+		return err, false
+	}
+
+	message := mapping.Message
+	if message == "" {
+		message = mapping.Code.String()
+	}
+
+	return NewServiceError(mapping.Code, message,
+		WithType(mapping.ErrorType),
+		WithClientProps(mapping.InternalErrorCode, message, nil),
+		WithOriginalError(err),
+	), true
+}
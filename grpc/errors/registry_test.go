@@ -0,0 +1,94 @@
+package errors_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	grpcerrors "github.com/rainbow-me/platform-tools/grpc/errors"
+)
+
+var errWalletNotFound = errors.New("wallet not found")
+
+type limitExceededError struct {
+	limit int
+}
+
+func (e *limitExceededError) Error() string {
+	return fmt.Sprintf("limit %d exceeded", e.limit)
+}
+
+func TestRegistry_Translate(t *testing.T) {
+	registry := grpcerrors.NewRegistry()
+	registry.Register(errWalletNotFound, grpcerrors.Mapping{
+		Code:              codes.NotFound,
+		InternalErrorCode: 1001,
+		ErrorType:         "Wallet",
+		Message:           "Wallet not found",
+	})
+	grpcerrors.RegisterType[*limitExceededError](registry, grpcerrors.Mapping{
+		Code:              codes.ResourceExhausted,
+		InternalErrorCode: 1002,
+	})
+
+	tests := []struct {
+		name           string
+		err            error
+		wantTranslated bool
+		wantCode       codes.Code
+		wantInternal   int32
+		wantMessage    string
+	}{
+		{
+			name:           "wrapped sentinel",
+			err:            fmt.Errorf("select wallet 0xabc: %w", errWalletNotFound),
+			wantTranslated: true,
+			wantCode:       codes.NotFound,
+			wantInternal:   1001,
+			wantMessage:    "Wallet not found",
+		},
+		{
+			name:           "wrapped error type without message",
+			err:            fmt.Errorf("transfer: %w", &limitExceededError{limit: 10}),
+			wantTranslated: true,
+			wantCode:       codes.ResourceExhausted,
+			wantInternal:   1002,
+			wantMessage:    codes.ResourceExhausted.String(),
+		},
+		{
+			name:     "unregistered error",
+			err:      errors.New("boom"),
+			wantCode: codes.Unknown,
+		},
+		{
+			name:     "status error is left untouched",
+			err:      status.Error(codes.AlreadyExists, "exists"),
+			wantCode: codes.AlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translated, ok := registry.Translate(tt.err)
+			require.Equal(t, tt.wantTranslated, ok)
+
+			st := status.Convert(translated)
+			require.Equal(t, tt.wantCode, st.Code())
+			if !tt.wantTranslated {
+				require.Equal(t, tt.err, translated)
+				return
+			}
+			require.Equal(t, tt.wantMessage, st.Message())
+
+			backendErr, err := grpcerrors.ParseBackendServiceError(translated)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantInternal, backendErr.GetPublic().GetInternalErrorCode())
+			require.Equal(t, tt.wantMessage, backendErr.GetPublic().GetCustomMessage())
+			require.Equal(t, tt.err.Error(), backendErr.GetPrivate().GetRawError())
+		})
+	}
+}
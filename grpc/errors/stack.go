@@ -0,0 +1,66 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	crerrors "github.com/cockroachdb/errors"
+	pkgerrors "github.com/pkg/errors"
+
+	"github.com/rainbow-me/platform-tools/common/env"
+)
+
+// stackTracer is implemented by errors carrying a stack trace: pkg/errors and the cockroachdb/errors wrappers.
+type stackTracer interface {
+	StackTrace() pkgerrors.StackTrace
+}
+
+// ErrorStack returns the stack trace recorded by cockroachdb/errors or pkg/errors in err's chain, one frame
+// per line. When several errors of the chain carry a stack, the innermost one is used, as it points to
+// where the error originated. It returns "" when err has no stack trace.
+func ErrorStack(err error) string {
+	var stack pkgerrors.StackTrace
+	for ; err != nil; err = errors.Unwrap(err) {
+		if tracer, ok := err.(stackTracer); ok { //nolint:errorlint // the chain is walked explicitly
+			stack = tracer.StackTrace()
+		}
+	}
+	if len(stack) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%+v", stack), "\n")
+}
+
+// SafeErrorMessage returns the message of err suitable for reporting outside of the service logs.
+// In production, unsafe details are redacted: only values marked safe with cockroachdb/errors
+// (format strings of errors.Newf/Wrapf, errors.Safe, redact.Safe) are kept, the rest is replaced by ×.
+// Other environments return the full message.
+func SafeErrorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	if redactUnsafeDetails() {
+		return crerrors.Redact(err)
+	}
+	return err.Error()
+}
+
+// FormatRawError returns the representation of err stored in Private.RawError: the message returned by
+// SafeErrorMessage followed, when available, by the stack trace returned by ErrorStack.
+func FormatRawError(err error) string {
+	if err == nil {
+		return ""
+	}
+	message := SafeErrorMessage(err)
+	if stack := ErrorStack(err); stack != "" {
+		return message + "\n" + stack
+	}
+	return message
+}
+
+// redactUnsafeDetails reports whether unsafe error details must be redacted in the current environment.
+func redactUnsafeDetails() bool {
+	appEnv, _ := env.GetApplicationEnv()
+	return appEnv == env.EnvironmentProduction
+}
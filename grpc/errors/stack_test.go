@@ -0,0 +1,52 @@
+package errors_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	crerrors "github.com/cockroachdb/errors"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/rainbow-me/platform-tools/common/env"
+	grpcerrors "github.com/rainbow-me/platform-tools/grpc/errors"
+)
+
+func TestErrorStack(t *testing.T) {
+	require.Empty(t, grpcerrors.ErrorStack(errors.New("no stack")))
+
+	cockroachErr := fmt.Errorf("handler: %w", crerrors.Newf("wallet %s not found", "0xabc"))
+	require.Contains(t, grpcerrors.ErrorStack(cockroachErr), "stack_test.go")
+
+	pkgErr := pkgerrors.Wrap(pkgerrors.New("db down"), "query")
+	require.Contains(t, grpcerrors.ErrorStack(pkgErr), "TestErrorStack")
+}
+
+func TestWithOriginalError_Redaction(t *testing.T) {
+	rawErr := crerrors.Newf("wallet %s not found", "0xabc")
+
+	tests := []struct {
+		name        string
+		environment env.Environment
+		wantMessage string
+	}{
+		{name: "development keeps unsafe details", environment: env.EnvironmentDevelopment, wantMessage: "wallet 0xabc not found"},
+		{name: "production redacts unsafe details", environment: env.EnvironmentProduction, wantMessage: "wallet × not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(env.ApplicationEnvKey, tt.environment.String())
+
+			err := grpcerrors.NewServiceError(codes.Internal, "lookup failed", grpcerrors.WithOriginalError(rawErr))
+			backendErr, parseErr := grpcerrors.ParseBackendServiceError(err)
+			require.NoError(t, parseErr)
+
+			raw := backendErr.GetPrivate().GetRawError()
+			require.Contains(t, raw, tt.wantMessage+"\n")
+			require.Contains(t, raw, "TestWithOriginalError_Redaction")
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package errors
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/anypb"
+
+	errorpb "github.com/rainbow-me/platform-tools/grpc/protos/gen/go/error"
+)
+
+// WithTranslationKey adds a TranslationKey to Public.Details, so that the gateway returns the message
+// translated in the client's language. The CustomMessage, if any, is used when no translation is found.
+func WithTranslationKey(key string, params map[string]string) ServiceErrorOption {
+	return func(detail *ServiceErrorWrapper) {
+		translation, err := anypb.New(&errorpb.TranslationKey{Key: key, Params: params})
+		if err != nil {
+			if detail.optionErr == nil {
+				detail.optionErr = fmt.Errorf("failed to marshal TranslationKey %s: %w", key, err)
+			}
+			return
+		}
+		detail.Detail.Public.Details = append(detail.Detail.Public.Details, translation)
+	}
+}
+
+// ParseTranslationKey returns the TranslationKey carried in the Public.Details of backendErr, if any.
+func ParseTranslationKey(backendErr *errorpb.BackendServiceError) (*errorpb.TranslationKey, bool) {
+	for _, detail := range backendErr.GetPublic().GetDetails() {
+		var translation errorpb.TranslationKey
+		if !detail.MessageIs(&translation) {
+			continue
+		}
+		if err := detail.UnmarshalTo(&translation); err != nil {
+			continue
+		}
+		return &translation, true
+	}
+	return nil, false
+}
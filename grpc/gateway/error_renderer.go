@@ -0,0 +1,252 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/tracer"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/rainbow-me/platform-tools/common/headers"
+	"github.com/rainbow-me/platform-tools/common/logger"
+	grpcerrors "github.com/rainbow-me/platform-tools/grpc/errors"
+	errorpb "github.com/rainbow-me/platform-tools/grpc/protos/gen/go/error"
+)
+
+// ErrorResponse is the stable JSON envelope returned to REST clients when a call fails.
+// It is built exclusively from the Public section of the BackendServiceError and the client-safe google.rpc
+// status details, so private debugging information (raw errors, stack traces, metadata) never leaves the backend.
+type ErrorResponse struct {
+	Code              string            `json:"code"`                          // gRPC code name, e.g. NOT_FOUND
+	InternalErrorCode int32             `json:"internal_error_code,omitempty"` // Public.InternalErrorCode
+	Message           string            `json:"message"`                       // Translated message, Public.CustomMessage or a generic message
+	Details           []json.RawMessage `json:"details,omitempty"`             // Public.Details and clientSafeDetails as protojson
+	RequestID         string            `json:"request_id,omitempty"`
+	TraceID           string            `json:"trace_id,omitempty"`
+}
+
+// clientSafeDetails are the google.rpc status details rendered in the ErrorResponse, e.g. the BadRequest field
+// violations of WithBadRequest. Others, like google.rpc.DebugInfo, carry debugging information and are dropped.
+var clientSafeDetails = map[protoreflect.FullName]bool{
+	proto.MessageName(&errdetails.ErrorInfo{}):           true,
+	proto.MessageName(&errdetails.BadRequest{}):          true,
+	proto.MessageName(&errdetails.RetryInfo{}):           true,
+	proto.MessageName(&errdetails.QuotaFailure{}):        true,
+	proto.MessageName(&errdetails.PreconditionFailure{}): true,
+	proto.MessageName(&errdetails.ResourceInfo{}):        true,
+	proto.MessageName(&errdetails.LocalizedMessage{}):    true,
+	proto.MessageName(&errdetails.Help{}):                true,
+}
+
+// genericErrorMessages holds the client-facing message used when no custom message is provided.
+var genericErrorMessages = map[codes.Code]string{
+	codes.Canceled:           "The request was cancelled",
+	codes.Unknown:            "An unexpected error occurred",
+	codes.InvalidArgument:    "The request is invalid",
+	codes.DeadlineExceeded:   "The request timed out",
+	codes.NotFound:           "The requested resource was not found",
+	codes.AlreadyExists:      "The resource already exists",
+	codes.PermissionDenied:   "Permission denied",
+	codes.ResourceExhausted:  "Too many requests",
+	codes.FailedPrecondition: "The request cannot be processed in the current state",
+	codes.Aborted:            "The request was aborted",
+	codes.OutOfRange:         "The request is out of range",
+	codes.Unimplemented:      "Not implemented",
+	codes.Internal:           "Internal server error",
+	codes.Unavailable:        "The service is temporarily unavailable",
+	codes.DataLoss:           "Internal server error",
+	codes.Unauthenticated:    "Authentication required",
+}
+
+// GenericErrorMessage returns the client-facing message used for the given code when the error
+// does not carry a Public.CustomMessage.
+func GenericErrorMessage(code codes.Code) string {
+	if msg, ok := genericErrorMessages[code]; ok {
+		return msg
+	}
+	return genericErrorMessages[codes.Unknown]
+}
+
+// HTTPStatusFromCode maps a gRPC code to an HTTP status, applying the overrides configured
+// with WithHTTPStatusMapping on top of the grpc-gateway defaults.
+func (g *Gateway) HTTPStatusFromCode(code codes.Code) int {
+	if httpStatus, ok := g.HTTPStatusOverrides[code]; ok {
+		return httpStatus
+	}
+	return runtime.HTTPStatusFromCode(code)
+}
+
+// httpStatusFor returns the HTTP status of an error, overridden for its internal error code if any.
+func (g *Gateway) httpStatusFor(code codes.Code, internalErrorCode int32) int {
+	if httpStatus, ok := g.ErrorCodeHTTPStatuses[internalErrorCode]; ok && internalErrorCode != 0 {
+		return httpStatus
+	}
+	return g.HTTPStatusFromCode(code)
+}
+
+// bodyTooLarge reports whether reading the request body stopped at the limit of an http.MaxBytesReader, e.g. set
+// by server.WithHTTPMaxBodyBytes. The generated handlers report it as an InvalidArgument status, losing the
+// *http.MaxBytesError, but the reader keeps returning it.
+func bodyTooLarge(r *http.Request) bool {
+	if r.Body == nil {
+		return false
+	}
+	var maxBytesErr *http.MaxBytesError
+	_, err := r.Body.Read(nil)
+	return errors.As(err, &maxBytesErr)
+}
+
+// ProtoMessageErrorHandler handles gRPC errors and renders them as a client-safe ErrorResponse
+func (g *Gateway) ProtoMessageErrorHandler(
+	ctx context.Context,
+	_ *runtime.ServeMux,
+	_ runtime.Marshaler,
+	w http.ResponseWriter,
+	r *http.Request,
+	err error,
+) {
+	g.Logger.Error("gRPC error",
+		logger.Error(err),
+		logger.String("path", r.URL.Path),
+	)
+
+	// Routing errors raised by the gateway mux itself carry their own HTTP status
+	httpStatus := 0
+	var httpStatusErr *runtime.HTTPStatusError
+	if errors.As(err, &httpStatusErr) {
+		httpStatus = httpStatusErr.HTTPStatus
+		err = httpStatusErr.Err
+	}
+
+	st := status.Convert(err)
+	body := g.buildErrorResponse(ctx, r, err, st)
+	switch {
+	case httpStatus != 0:
+	case st.Code() == codes.InvalidArgument && bodyTooLarge(r):
+		httpStatus = http.StatusRequestEntityTooLarge
+	default:
+		httpStatus = g.httpStatusFor(st.Code(), body.InternalErrorCode)
+	}
+
+	// Forward response headers (e.g. request ID) set by the gRPC server
+	_ = g.ResponseHeaderHandler(ctx, w, nil)
+
+	buf, marshalErr := json.Marshal(body)
+	if marshalErr != nil {
+		g.Logger.Error("Failed to marshal error response", logger.Error(marshalErr))
+		http.Error(w, `{"code":"INTERNAL","message":"Internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Del("Trailer")
+	w.Header().Del("Transfer-Encoding")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	if _, writeErr := w.Write(buf); writeErr != nil {
+		g.Logger.Warn("Failed to write error response", logger.Error(writeErr))
+	}
+}
+
+// buildErrorResponse creates the ErrorResponse for err using only client-safe information.
+func (g *Gateway) buildErrorResponse(
+	ctx context.Context,
+	r *http.Request,
+	err error,
+	st *status.Status,
+) ErrorResponse {
+	body := ErrorResponse{
+		Code:      code.Code(st.Code()).String(), //nolint:gosec // gRPC codes are small positive integers
+		Message:   GenericErrorMessage(st.Code()),
+		RequestID: requestIDFromResponse(ctx, r),
+		TraceID:   traceIDFromResponse(ctx),
+	}
+
+	if backendErr, parseErr := grpcerrors.ParseBackendServiceError(err); parseErr == nil {
+		public := backendErr.GetPublic()
+		body.InternalErrorCode = public.GetInternalErrorCode()
+		if public.CustomMessage != nil && public.GetCustomMessage() != "" {
+			body.Message = public.GetCustomMessage()
+		}
+		if msg, ok := g.localizedMessage(r, backendErr); ok {
+			body.Message = msg
+		}
+		for _, detail := range public.GetDetails() {
+			g.appendDetail(&body, detail)
+		}
+	}
+
+	for _, detail := range st.Proto().GetDetails() {
+		if clientSafeDetails[detail.MessageName()] {
+			g.appendDetail(&body, detail)
+		}
+	}
+
+	return body
+}
+
+// appendDetail renders detail as protojson, with its @type, into the details of body.
+func (g *Gateway) appendDetail(body *ErrorResponse, detail *anypb.Any) {
+	rendered, marshalErr := protojson.Marshal(detail)
+	if marshalErr != nil {
+		g.Logger.Warn("Failed to render public error detail",
+			logger.String("type_url", detail.GetTypeUrl()),
+			logger.Error(marshalErr),
+		)
+		return
+	}
+	body.Details = append(body.Details, rendered)
+}
+
+// localizedMessage translates the TranslationKey of backendErr, if any, in the language preferred by the client.
+func (g *Gateway) localizedMessage(r *http.Request, backendErr *errorpb.BackendServiceError) (string, bool) {
+	if g.MessageBundle == nil {
+		return "", false
+	}
+	translation, ok := grpcerrors.ParseTranslationKey(backendErr)
+	if !ok {
+		return "", false
+	}
+	msg, _, ok := g.MessageBundle.Localize(r.Header.Get("Accept-Language"), translation.GetKey(), translation.GetParams())
+	if !ok {
+		g.Logger.Warn("Missing translation for error message", logger.String("key", translation.GetKey()))
+	}
+	return msg, ok
+}
+
+// requestIDFromResponse returns the request ID sent by the client or, when missing,
+// the one generated by the gRPC server and returned in the response headers.
+func requestIDFromResponse(ctx context.Context, r *http.Request) string {
+	if requestID := r.Header.Get(headers.HeaderXRequestID); requestID != "" {
+		return requestID
+	}
+	if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+		if values := md.HeaderMD.Get(headers.HeaderXRequestID); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// traceIDFromResponse returns the trace ID returned by the gRPC server or, when missing,
+// the one of the active span.
+func traceIDFromResponse(ctx context.Context) string {
+	if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+		if values := md.HeaderMD.Get(headers.HeaderXTraceID); len(values) > 0 {
+			return values[0]
+		}
+	}
+	if span, ok := tracer.SpanFromContext(ctx); ok {
+		return span.Context().TraceID()
+	}
+	return ""
+}
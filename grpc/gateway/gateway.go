@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -11,12 +12,14 @@ import (
 	"github.com/gorilla/handlers"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/rainbow-me/platform-tools/common/headers"
+	"github.com/rainbow-me/platform-tools/common/i18n"
 	"github.com/rainbow-me/platform-tools/common/logger"
 )
 
@@ -26,11 +29,19 @@ const (
 	DefaultServerAddress = ":9090"
 	// DefaultTimeout for gRPC dial
 	DefaultTimeout = 30 * time.Second
+	// InProcessAddress is the ServerAddress used by WithInProcessListener. The passthrough scheme skips
+	// name resolution, the address itself is ignored by the in-memory dialer.
+	InProcessAddress = "passthrough:///in-process"
 )
 
 // Option is a functional option that modifies the REST Gateway on initialization
 type Option func(*Gateway)
 
+// InProcessDialer opens in-memory connections to a gRPC server of the same process, e.g. *bufconn.Listener
+type InProcessDialer interface {
+	DialContext(ctx context.Context) (net.Conn, error)
+}
+
 type RegisterFunc func(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error
 
 // WithDialOptions assigns a list of gRPC dial options to the REST Gateway
@@ -94,6 +105,54 @@ func WithTLS(creds grpc.DialOption) Option {
 	}
 }
 
+// WithInProcessListener connects to the gRPC server serving lis in the same process instead of dialing
+// ServerAddress: requests go through memory, without TLS, and still run the server's interceptors.
+func WithInProcessListener(lis InProcessDialer) Option {
+	return func(g *Gateway) {
+		g.ServerAddress = InProcessAddress
+		g.ServerDialOptions = []grpc.DialOption{
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+		}
+	}
+}
+
+// WithHTTPStatusMapping overrides the HTTP status returned for the given gRPC codes.
+// Codes that are not overridden use the grpc-gateway default mapping.
+func WithHTTPStatusMapping(mapping map[codes.Code]int) Option {
+	return func(g *Gateway) {
+		if g.HTTPStatusOverrides == nil {
+			g.HTTPStatusOverrides = make(map[codes.Code]int, len(mapping))
+		}
+		for code, httpStatus := range mapping {
+			g.HTTPStatusOverrides[code] = httpStatus
+		}
+	}
+}
+
+// WithErrorCodeHTTPStatus overrides the HTTP status returned for the given internal error codes, such as the
+// HTTPStatuses map generated from an error catalog. It takes precedence over WithHTTPStatusMapping.
+func WithErrorCodeHTTPStatus(mapping map[int32]int) Option {
+	return func(g *Gateway) {
+		if g.ErrorCodeHTTPStatuses == nil {
+			g.ErrorCodeHTTPStatuses = make(map[int32]int, len(mapping))
+		}
+		for code, httpStatus := range mapping {
+			g.ErrorCodeHTTPStatuses[code] = httpStatus
+		}
+	}
+}
+
+// WithMessageBundle sets the message bundle used to translate the TranslationKey of error responses
+// according to the request's Accept-Language.
+func WithMessageBundle(bundle *i18n.Bundle) Option {
+	return func(g *Gateway) {
+		g.MessageBundle = bundle
+	}
+}
+
 // WithHeadersToForward specifies which headers to forward to gRPC
 func WithHeadersToForward(headers ...string) Option {
 	return func(g *Gateway) {
@@ -133,17 +192,20 @@ func WithCORS(opts ...CORSOption) Option {
 }
 
 type Gateway struct {
-	ServerAddress        string
-	ServerDialOptions    []grpc.DialOption
-	Endpoints            map[string][]RegisterFunc
-	Mux                  *http.ServeMux
-	GatewayMuxOptions    []runtime.ServeMuxOption
-	HeaderConfig         headers.HeaderConfig
-	Logger               *logger.Logger
-	Timeout              time.Duration
-	EnableRequestLogging bool
-	EnableCompression    bool
-	CORS                 *CORS
+	ServerAddress         string
+	ServerDialOptions     []grpc.DialOption
+	Endpoints             map[string][]RegisterFunc
+	Mux                   *http.ServeMux
+	GatewayMuxOptions     []runtime.ServeMuxOption
+	HeaderConfig          headers.HeaderConfig
+	Logger                *logger.Logger
+	Timeout               time.Duration
+	EnableRequestLogging  bool
+	EnableCompression     bool
+	CORS                  *CORS
+	HTTPStatusOverrides   map[codes.Code]int
+	ErrorCodeHTTPStatuses map[int32]int
+	MessageBundle         *i18n.Bundle
 }
 
 // NewGateway creates a gRPC REST Gateway with HTTP handlers that have been
@@ -334,22 +396,6 @@ func (g *Gateway) OutgoingHeaderMatcher(key string) (string, bool) {
 	}
 }
 
-// ProtoMessageErrorHandler handles gRPC errors and converts them to appropriate HTTP responses
-func (g *Gateway) ProtoMessageErrorHandler(
-	ctx context.Context,
-	mux *runtime.ServeMux,
-	marshaller runtime.Marshaler,
-	w http.ResponseWriter,
-	r *http.Request,
-	err error,
-) {
-	g.Logger.Error("gRPC error",
-		logger.Error(err),
-		logger.String("path", r.URL.Path),
-	)
-	runtime.DefaultHTTPErrorHandler(ctx, mux, marshaller, w, r, err)
-}
-
 // ResponseHeaderHandler processes gRPC response metadata and sets HTTP response headers
 func (g *Gateway) ResponseHeaderHandler(ctx context.Context, w http.ResponseWriter, _ proto.Message) error {
 	// Extract gRPC response metadata
@@ -2,22 +2,31 @@ package gateway_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/rainbow-me/platform-tools/common/headers"
+	"github.com/rainbow-me/platform-tools/common/i18n"
 	"github.com/rainbow-me/platform-tools/common/logger"
 	"github.com/rainbow-me/platform-tools/common/test"
+	grpcerrors "github.com/rainbow-me/platform-tools/grpc/errors"
 	"github.com/rainbow-me/platform-tools/grpc/gateway"
 	testpb "github.com/rainbow-me/platform-tools/grpc/protos/gen/go/test"
 )
@@ -243,6 +252,182 @@ func TestGateway_protoMessageErrorHandler(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
+func TestGateway_protoMessageErrorHandler_BodyTooLarge(t *testing.T) {
+	g := &gateway.Gateway{Logger: logger.NoOp()}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"larger than ten bytes"}`))
+	r.Body = http.MaxBytesReader(w, r.Body, 10)
+	_, readErr := io.ReadAll(r.Body)
+
+	// As reported by the generated handlers
+	err := status.Errorf(codes.InvalidArgument, "%v", readErr)
+	g.ProtoMessageErrorHandler(context.Background(), runtime.NewServeMux(), &runtime.JSONPb{}, w, r, err)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// Other invalid arguments are unaffected
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	r.Body = http.MaxBytesReader(w, r.Body, 10)
+	g.ProtoMessageErrorHandler(context.Background(), runtime.NewServeMux(), &runtime.JSONPb{}, w, r,
+		status.Error(codes.InvalidArgument, "missing name"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGateway_protoMessageErrorHandler_ClientSafe(t *testing.T) {
+	tests := []struct {
+		name       string
+		gateway    *gateway.Gateway
+		err        error
+		wantStatus int
+		wantBody   gateway.ErrorResponse
+	}{
+		{
+			name:    "backend service error renders public fields only",
+			gateway: &gateway.Gateway{Logger: logger.NoOp()},
+			err: grpcerrors.NewServiceError(codes.NotFound, "wallet 0xabc missing in db",
+				grpcerrors.WithClientProps(1001, "Wallet not found", nil),
+				grpcerrors.WithOriginalError(errors.New("sql: no rows in result set")),
+				grpcerrors.WithMetadata(map[string]string{"host": "db-1"}),
+			),
+			wantStatus: http.StatusNotFound,
+			wantBody: gateway.ErrorResponse{
+				Code:              "NOT_FOUND",
+				InternalErrorCode: 1001,
+				Message:           "Wallet not found",
+				RequestID:         "req-1",
+			},
+		},
+		{
+			name:       "plain status error uses generic message",
+			gateway:    &gateway.Gateway{Logger: logger.NoOp()},
+			err:        status.Error(codes.Internal, "dial tcp 10.0.0.1:5432: connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			wantBody: gateway.ErrorResponse{
+				Code:      "INTERNAL",
+				Message:   gateway.GenericErrorMessage(codes.Internal),
+				RequestID: "req-1",
+			},
+		},
+		{
+			name: "custom HTTP status mapping",
+			gateway: func() *gateway.Gateway {
+				g := &gateway.Gateway{Logger: logger.NoOp()}
+				gateway.WithHTTPStatusMapping(map[codes.Code]int{codes.FailedPrecondition: http.StatusConflict})(g)
+				return g
+			}(),
+			err:        status.Error(codes.FailedPrecondition, "state mismatch"),
+			wantStatus: http.StatusConflict,
+			wantBody: gateway.ErrorResponse{
+				Code:      "FAILED_PRECONDITION",
+				Message:   gateway.GenericErrorMessage(codes.FailedPrecondition),
+				RequestID: "req-1",
+			},
+		},
+		{
+			name: "internal error code HTTP status mapping",
+			gateway: func() *gateway.Gateway {
+				g := &gateway.Gateway{Logger: logger.NoOp()}
+				gateway.WithHTTPStatusMapping(map[codes.Code]int{codes.FailedPrecondition: http.StatusConflict})(g)
+				gateway.WithErrorCodeHTTPStatus(map[int32]int{1002: http.StatusLocked})(g)
+				return g
+			}(),
+			err: grpcerrors.NewServiceError(codes.FailedPrecondition, "wallet locked",
+				grpcerrors.WithClientProps(1002, "Wallet locked", nil),
+			),
+			wantStatus: http.StatusLocked,
+			wantBody: gateway.ErrorResponse{
+				Code:              "FAILED_PRECONDITION",
+				InternalErrorCode: 1002,
+				Message:           "Wallet locked",
+				RequestID:         "req-1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(headers.HeaderXRequestID, "req-1")
+
+			tt.gateway.ProtoMessageErrorHandler(context.Background(), runtime.NewServeMux(), &runtime.JSONPb{}, w, r, tt.err)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.NotContains(t, w.Body.String(), "sql")
+			assert.NotContains(t, w.Body.String(), "db-1")
+
+			var body gateway.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.wantBody, body)
+		})
+	}
+}
+
+func TestGateway_protoMessageErrorHandler_StatusDetails(t *testing.T) {
+	g := &gateway.Gateway{Logger: logger.NoOp()}
+	err := grpcerrors.NewServiceError(codes.InvalidArgument, "invalid transfer",
+		grpcerrors.WithClientProps(1003, "Invalid transfer", nil),
+		grpcerrors.WithBadRequest(grpcerrors.FieldViolation("amount", "must be positive")),
+		grpcerrors.WithRetryInfo(2*time.Second),
+		grpcerrors.WithStatusDetails(&errdetails.DebugInfo{Detail: "panic in ledger.go:42"}),
+	)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	g.ProtoMessageErrorHandler(context.Background(), runtime.NewServeMux(), &runtime.JSONPb{}, w, r, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NotContains(t, w.Body.String(), "ledger.go")
+
+	var body struct {
+		Details []map[string]any `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Details, 2)
+	assert.Equal(t, "type.googleapis.com/google.rpc.BadRequest", body.Details[0]["@type"])
+	assert.Equal(t, []any{map[string]any{"field": "amount", "description": "must be positive"}},
+		body.Details[0]["fieldViolations"])
+	assert.Equal(t, "type.googleapis.com/google.rpc.RetryInfo", body.Details[1]["@type"])
+	assert.Equal(t, "2s", body.Details[1]["retryDelay"])
+}
+
+func TestGateway_protoMessageErrorHandler_Localized(t *testing.T) {
+	g := &gateway.Gateway{Logger: logger.NoOp()}
+	gateway.WithMessageBundle(i18n.NewBundle(map[language.Tag]map[string]string{
+		language.English: {"wallet.not_found": "Wallet {address} not found"},
+		language.French:  {"wallet.not_found": "Portefeuille {address} introuvable"},
+	}))(g)
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		key            string
+		wantMessage    string
+	}{
+		{name: "preferred language", acceptLanguage: "fr-FR,fr;q=0.9,en;q=0.8", key: "wallet.not_found", wantMessage: "Portefeuille 0xabc introuvable"},
+		{name: "unsupported language falls back to English", acceptLanguage: "de", key: "wallet.not_found", wantMessage: "Wallet 0xabc not found"},
+		{name: "unknown key keeps custom message", acceptLanguage: "fr", key: "wallet.unknown", wantMessage: "Wallet not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := grpcerrors.NewServiceError(codes.NotFound, "wallet missing",
+				grpcerrors.WithClientProps(1001, "Wallet not found", nil),
+				grpcerrors.WithTranslationKey(tt.key, map[string]string{"address": "0xabc"}),
+			)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Language", tt.acceptLanguage)
+
+			g.ProtoMessageErrorHandler(context.Background(), runtime.NewServeMux(), &runtime.JSONPb{}, w, r, err)
+
+			var body gateway.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.wantMessage, body.Message)
+		})
+	}
+}
+
 func TestGateway_responseHeaderHandler(t *testing.T) {
 	g := &gateway.Gateway{
 		HeaderConfig: headers.HeaderConfig{
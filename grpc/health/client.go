@@ -2,11 +2,13 @@ package health
 
 import (
 	"context"
+	"crypto/tls"
 	"time"
 
 	"github.com/cockroachdb/errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health/grpc_health_v1"
 )
@@ -15,6 +17,7 @@ import (
 type config struct {
 	target            string
 	secure            bool
+	tlsConfig         *tls.Config
 	backoffConfig     backoff.Config
 	minConnectTimeout time.Duration
 	dialOptions       []grpc.DialOption
@@ -32,13 +35,27 @@ func WithTarget(target string) Option {
 
 // WithSecure enables or disables secure (TLS) connection. If false (default),
 // uses insecure credentials unless overridden.
-// If true, no credentials are set by default; provide custom TLS credentials via WithDialOptions if needed.
+// If true, no credentials are set by default; provide custom TLS credentials via WithDialOptions if needed,
+// or use WithTLSConfig.
 func WithSecure(secure bool) Option {
 	return func(c *config) {
 		c.secure = secure
 	}
 }
 
+// WithTLSConfig enables a secure connection using the given TLS configuration, e.g. the ClientTLS of the
+// tlsconfig.Certificates served by the target for mTLS. A nil configuration verifies the server against the
+// system roots.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *config) {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		c.secure = true
+		c.tlsConfig = tlsConfig
+	}
+}
+
 // WithBackoffConfig sets the backoff configuration for connection attempts.
 // Uses backoff.Config to avoid deprecated types.
 func WithBackoffConfig(bc backoff.Config) Option {
@@ -120,7 +137,10 @@ func NewHealthChecker(opts ...Option) (*Checker, error) {
 	}
 
 	var dialOpts []grpc.DialOption
-	if !c.secure {
+	switch {
+	case c.tlsConfig != nil:
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(c.tlsConfig)))
+	case !c.secure:
 		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 
@@ -30,6 +30,27 @@ func (e *contextStatusError) Unwrap() error {
 	return e.error
 }
 
+// contextStatus maps a context.Canceled or context.DeadlineExceeded error that is not a gRPC status error yet to
+// its status with status.FromContextError, keeping the original error. With sanitize, the status message is the
+// generic one rather than the wrapped error's. It returns nil for other errors.
+func contextStatus(err error, sanitize bool) error {
+	if _, isStatus := status.FromError(err); isStatus {
+		return nil
+	}
+	if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+	st := status.FromContextError(err)
+	switch {
+	case !sanitize:
+	case st.Code() == codes.Canceled:
+		st = statusCanceled
+	default:
+		st = statusDeadlineExceeded
+	}
+	return &contextStatusError{Status: st, error: err}
+}
+
 // UnaryContextStatusInterceptor maps context-related errors to proper gRPC status codes.
 //
 // Specifically:
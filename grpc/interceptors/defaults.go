@@ -8,6 +8,7 @@ import (
 
 	"github.com/rainbow-me/platform-tools/common/logger"
 	"github.com/rainbow-me/platform-tools/grpc/auth"
+	"github.com/rainbow-me/platform-tools/grpc/errors"
 )
 
 const (
@@ -30,6 +31,9 @@ type Config struct {
 
 	// Authentication settings
 	Auth *auth.Config
+
+	// ErrorRegistry translates domain errors returned by handlers into gRPC status errors
+	ErrorRegistry *errors.Registry
 }
 
 // ConfigOption is a functional option for configuring the interceptor chain
@@ -92,6 +96,14 @@ func WithAuthOptions(opts ...auth.ConfigOption) ConfigOption {
 	}
 }
 
+// WithErrorRegistry sets the registry used by the errors interceptor to translate domain errors.
+// Defaults to errors.DefaultRegistry.
+func WithErrorRegistry(registry *errors.Registry) ConfigOption {
+	return func(c *Config) {
+		c.ErrorRegistry = registry
+	}
+}
+
 // NewConfig creates a new configuration with sensible defaults
 func NewConfig(serviceName, environment string, opts ...ConfigOption) *Config {
 	// Set sensible defaults
@@ -123,6 +135,8 @@ func NewConfig(serviceName, environment string, opts ...ConfigOption) *Config {
 				healthCheckMethod: true, // Skip auth for health check method by default
 			},
 		},
+
+		ErrorRegistry: errors.DefaultRegistry,
 	}
 
 	// Apply functional options
@@ -181,7 +195,7 @@ func NewDefaultServerUnaryChain(
 	}
 
 	// add errors handling
-	chain.Push("errors", UnaryErrorServerInterceptor)
+	chain.Push("errors", NewUnaryErrorServerInterceptor(ErrorRegistry(cfg.ErrorRegistry)))
 
 	// Add authentication interceptor if enabled
 	if cfg.Auth != nil && cfg.Auth.Enabled {
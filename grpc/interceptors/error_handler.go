@@ -4,43 +4,141 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 
 	"github.com/DataDog/dd-trace-go/v2/ddtrace/ext"
 	"github.com/DataDog/dd-trace-go/v2/ddtrace/tracer"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
+	"github.com/rainbow-me/platform-tools/common/correlation"
 	"github.com/rainbow-me/platform-tools/common/env"
+	"github.com/rainbow-me/platform-tools/common/headers"
+	"github.com/rainbow-me/platform-tools/common/logger"
+	commonmeta "github.com/rainbow-me/platform-tools/common/metadata"
+	"github.com/rainbow-me/platform-tools/grpc/errors"
+	"github.com/rainbow-me/platform-tools/observability"
 )
 
+const (
+	errorReferenceKey = "error_reference"
+	errorStackKey     = "error_stack"
+
+	// errorRawMessageTag is the span tag holding the message of a domain error translated by the registry
+	errorRawMessageTag = "error.raw_message"
+)
+
+// ErrorHandlerConfig holds the configuration of the error interceptors.
+type ErrorHandlerConfig struct {
+	// Registry translates domain errors into gRPC status errors. Defaults to errors.DefaultRegistry.
+	Registry *errors.Registry
+
+	// SanitizeUnexpected turns errors that are not gRPC status errors into a generic codes.Internal error,
+	// so that raw messages (SQL, hostnames, wrapped internals) never reach clients. The original error is
+	// kept in logs and span tags. Defaults to true in the production environment.
+	SanitizeUnexpected bool
+}
+
+// ErrorHandlerOption is a functional option for configuring the error interceptors.
+type ErrorHandlerOption func(*ErrorHandlerConfig)
+
+// ErrorRegistry sets the registry used to translate domain errors into gRPC status errors.
+func ErrorRegistry(registry *errors.Registry) ErrorHandlerOption {
+	return func(c *ErrorHandlerConfig) {
+		c.Registry = registry
+	}
+}
+
+// SanitizeUnexpectedErrors enables or disables sanitization of non-status errors, overriding the
+// environment based default.
+func SanitizeUnexpectedErrors(enabled bool) ErrorHandlerOption {
+	return func(c *ErrorHandlerConfig) {
+		c.SanitizeUnexpected = enabled
+	}
+}
+
+func errorHandlerConfig(opts ...ErrorHandlerOption) *ErrorHandlerConfig {
+	appEnv, _ := env.GetApplicationEnv()
+	cfg := &ErrorHandlerConfig{
+		Registry:           errors.DefaultRegistry,
+		SanitizeUnexpected: appEnv == env.EnvironmentProduction,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
 // UnaryErrorServerInterceptor is a gRPC unary server interceptor that handles errors returned by handlers.
-// It detects all errors, tags them in tracing spans, and can be extended for logging and wrapping.
+// It detects all errors, translates registered domain errors using errors.DefaultRegistry, tags them
+// in tracing spans and, in production, sanitizes unexpected non-status errors.
 func UnaryErrorServerInterceptor(
 	ctx context.Context,
 	req interface{},
-	_ *grpc.UnaryServerInfo,
+	info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (interface{}, error) {
-	resp, err := handler(ctx, req)
-	err = handleError(ctx, err)
-	return resp, err
+	return defaultUnaryErrorServerInterceptor()(ctx, req, info, handler)
 }
 
 // GrpcErrorStreamingInterceptor is a gRPC streaming server interceptor that handles errors returned by handlers.
-// It detects all errors, tags them in tracing spans, and can be extended for logging and wrapping.
+// It detects all errors, translates registered domain errors using errors.DefaultRegistry, tags them
+// in tracing spans and, in production, sanitizes unexpected non-status errors.
 func GrpcErrorStreamingInterceptor(
 	srv interface{},
 	ss grpc.ServerStream,
-	_ *grpc.StreamServerInfo,
+	info *grpc.StreamServerInfo,
 	handler grpc.StreamHandler,
 ) error {
-	err := handler(srv, ss)
-	err = handleError(ss.Context(), err)
-	return err
+	return defaultStreamErrorServerInterceptor()(srv, ss, info, handler)
+}
+
+// The default interceptors are built on first use, once the application environment is set up
+var (
+	defaultUnaryErrorServerInterceptor = sync.OnceValue(func() grpc.UnaryServerInterceptor {
+		return NewUnaryErrorServerInterceptor()
+	})
+	defaultStreamErrorServerInterceptor = sync.OnceValue(func() grpc.StreamServerInterceptor {
+		return NewStreamErrorServerInterceptor()
+	})
+)
+
+// NewUnaryErrorServerInterceptor creates a gRPC unary server interceptor that handles errors returned by
+// handlers, configured with the given options.
+func NewUnaryErrorServerInterceptor(opts ...ErrorHandlerOption) grpc.UnaryServerInterceptor {
+	cfg := errorHandlerConfig(opts...)
+	return func(
+		ctx context.Context,
+		req interface{},
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		err = handleError(ctx, cfg, err)
+		return resp, err
+	}
+}
+
+// NewStreamErrorServerInterceptor creates a gRPC streaming server interceptor that handles errors returned by
+// handlers, configured with the given options.
+func NewStreamErrorServerInterceptor(opts ...ErrorHandlerOption) grpc.StreamServerInterceptor {
+	cfg := errorHandlerConfig(opts...)
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		_ *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		err := handler(srv, ss)
+		err = handleError(ss.Context(), cfg, err)
+		return err
+	}
 }
 
 // handleError processes the given error, if any.
-func handleError(ctx context.Context, err error) error {
+func handleError(ctx context.Context, cfg *ErrorHandlerConfig, err error) error {
 	if err == nil {
 		return nil
 	}
@@ -49,19 +147,90 @@ func handleError(ctx context.Context, err error) error {
 		_, _ = fmt.Fprintf(os.Stderr, "Error in grpc handler: %+v\n", err)
 	}
 
-	// Always tag the error in the tracing span for any error
+	// Client cancellations and deadlines keep their code, which clients rely on to retry: they are not unexpected
+	if ctxErr := contextStatus(err, cfg.SanitizeUnexpected); ctxErr != nil {
+		err = ctxErr
+	}
+
+	// Translate registered domain errors, keeping the original error in Private.RawError
+	var translatedFrom error
+	if cfg.Registry != nil {
+		if translated, ok := cfg.Registry.Translate(err); ok {
+			err, translatedFrom = translated, err
+		}
+	}
+
+	// Always tag the error in the tracing span for any error, with the code and message returned to the client
 	// ERROR TAG: Error detected and tagged in tracing span for all error types
-	setErrorSpan(ctx, err)
+	setErrorSpan(ctx, err, translatedFrom)
+
+	stack := errors.ErrorStack(err)
+	if translatedFrom != nil {
+		stack = errors.ErrorStack(translatedFrom)
+	}
+	if _, isStatus := status.FromError(err); cfg.SanitizeUnexpected && !isStatus {
+		return sanitizeUnexpectedError(ctx, err, stack)
+	}
+	if stack != "" {
+		// The logging interceptor reports the status, the stack is only known here
+		logger.FromContext(ctx).Warn("Error with stack trace in gRPC handler",
+			logger.Error(err),
+			logger.String(errorStackKey, stack),
+		)
+	}
 
 	return err
 }
 
+// sanitizeUnexpectedError replaces an error that is not a gRPC status error with a generic codes.Internal
+// error referencing the request, after logging the original error with its stack and recording it on the span.
+func sanitizeUnexpectedError(ctx context.Context, err error, stack string) error {
+	reference := errorReference(ctx)
+
+	fields := []logger.Field{
+		logger.Error(err),
+		logger.String(errorReferenceKey, reference),
+	}
+	if stack != "" {
+		fields = append(fields, logger.String(errorStackKey, stack))
+	}
+	logger.FromContext(ctx).Error("Unexpected error in gRPC handler", fields...)
+	observability.SetTag(ctx, ext.ErrorDetails, errors.SafeErrorMessage(err))
+
+	message := "Internal server error"
+	if reference != "" {
+		message = fmt.Sprintf("%s (reference: %s)", message, reference)
+	}
+
+	return errors.NewServiceError(codes.Internal, message,
+		errors.WithType("Internal"),
+		errors.WithClientProps(0, message, nil),
+		errors.WithMetadata(map[string]string{errorReferenceKey: reference}),
+	)
+}
+
+// errorReference returns the ID clients can report to find the original error: the request ID when available,
+// the correlation ID otherwise.
+func errorReference(ctx context.Context) string {
+	if info, ok := commonmeta.GetRequestInfoFromContext(ctx); ok && info.RequestID != "" {
+		return info.RequestID
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(headers.HeaderXRequestID); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+	return correlation.ID(ctx)
+}
+
 // setErrorSpan tags the tracing span with error details if a span exists in the context.
 // This includes setting error flags, type, message, and stack trace for observability in tools like Datadog.
 // For gRPC status errors, it extracts the code and message specifically.
 // For non-status errors, it treats them as system errors.
+// err is the error returned to the client; translatedFrom, when the registry translated it, is the error returned
+// by the handler, whose message and stack are kept too.
 // ERROR TAG: Tagging error in tracing span with appropriate type, message, and stack
-func setErrorSpan(ctx context.Context, err error) {
+func setErrorSpan(ctx context.Context, err, translatedFrom error) {
 	span, ok := tracer.SpanFromContext(ctx)
 	if !ok {
 		return
@@ -71,17 +240,24 @@ func setErrorSpan(ctx context.Context, err error) {
 	s, isStatus := status.FromError(err)
 	if isStatus {
 		// For gRPC status errors, use the specific code as error type and the status message
+		span.SetTag(ext.ErrorType, s.Code().String())
 		span.SetTag(ext.ErrorMsg, s.Message())
 		// Set the gRPC status code as an integer for visibility in Datadog UI and metrics
 		span.SetTag("rpc.grpc.status", s.Code())
 		span.SetTag("rpc.grpc.status_code", int(s.Code()))
 		span.SetTag("rpc.grpc.status_message", s.Message())
 	} else {
-		// For non-gRPC status errors, treat as system error
+		// For non-gRPC status errors, treat as system error, redacting unsafe details in production
 		span.SetTag(ext.ErrorType, "system")
-		span.SetTag(ext.ErrorMsg, err.Error())
+		span.SetTag(ext.ErrorMsg, errors.SafeErrorMessage(err))
+	}
+	if translatedFrom != nil {
+		span.SetTag(errorRawMessageTag, errors.SafeErrorMessage(translatedFrom))
+		err = translatedFrom
 	}
 
-	// Set the error stack if available (works with pkg/errors wrapped errors)
-	// span.SetTag(ext.ErrorStack, fmt.Sprintf("%+v", err))
+	// Set the error stack if available (works with cockroachdb/errors and pkg/errors wrapped errors)
+	if stack := errors.ErrorStack(err); stack != "" {
+		span.SetTag(ext.ErrorStack, stack)
+	}
 }
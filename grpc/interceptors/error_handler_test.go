@@ -0,0 +1,205 @@
+package interceptors_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/ext"
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/mocktracer"
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/tracer"
+	crerrors "github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/rainbow-me/platform-tools/common/headers"
+	"github.com/rainbow-me/platform-tools/common/logger"
+	grpcerrors "github.com/rainbow-me/platform-tools/grpc/errors"
+	"github.com/rainbow-me/platform-tools/grpc/interceptors"
+)
+
+var errAccountLocked = errors.New("account locked")
+
+func TestUnaryErrorServerInterceptor_Registry(t *testing.T) {
+	registry := grpcerrors.NewRegistry()
+	registry.Register(errAccountLocked, grpcerrors.Mapping{
+		Code:              codes.FailedPrecondition,
+		InternalErrorCode: 3001,
+		ErrorType:         "Account",
+		Message:           "Account is locked",
+	})
+
+	interceptor := interceptors.NewUnaryErrorServerInterceptor(interceptors.ErrorRegistry(registry))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	tests := []struct {
+		name       string
+		handlerErr error
+		wantCode   codes.Code
+		wantMsg    string
+	}{
+		{
+			name:     "no error",
+			wantCode: codes.OK,
+		},
+		{
+			name:       "registered domain error",
+			handlerErr: fmt.Errorf("lock check: %w", errAccountLocked),
+			wantCode:   codes.FailedPrecondition,
+			wantMsg:    "Account is locked",
+		},
+		{
+			name:       "status error passes through",
+			handlerErr: status.Error(codes.NotFound, "not found"),
+			wantCode:   codes.NotFound,
+			wantMsg:    "not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := interceptor(context.Background(), nil, info, func(_ context.Context, _ any) (any, error) {
+				return nil, tt.handlerErr
+			})
+			st := status.Convert(err)
+			require.Equal(t, tt.wantCode, st.Code())
+			require.Equal(t, tt.wantMsg, st.Message())
+		})
+	}
+
+	_, err := interceptor(context.Background(), nil, info, func(_ context.Context, _ any) (any, error) {
+		return nil, errAccountLocked
+	})
+	backendErr, parseErr := grpcerrors.ParseBackendServiceError(err)
+	require.NoError(t, parseErr)
+	require.Equal(t, int32(3001), backendErr.GetPublic().GetInternalErrorCode())
+	require.Equal(t, errAccountLocked.Error(), backendErr.GetPrivate().GetRawError())
+}
+
+func TestUnaryErrorServerInterceptor_Span(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	registry := grpcerrors.NewRegistry()
+	registry.Register(errAccountLocked, grpcerrors.Mapping{Code: codes.FailedPrecondition, Message: "Account is locked"})
+	interceptor := interceptors.NewUnaryErrorServerInterceptor(interceptors.ErrorRegistry(registry),
+		interceptors.SanitizeUnexpectedErrors(false))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "grpc.server")
+	_, _ = interceptor(ctx, nil, info, func(_ context.Context, _ any) (any, error) {
+		return nil, fmt.Errorf("lock check: %w", errAccountLocked)
+	})
+	span.Finish()
+
+	// The span reports the translated error, and keeps the message of the handler error
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, "FailedPrecondition", spans[0].Tag(ext.ErrorType))
+	require.Equal(t, "Account is locked", spans[0].Tag(ext.ErrorMsg))
+	require.Equal(t, float64(codes.FailedPrecondition), spans[0].Tag("rpc.grpc.status_code"))
+	require.Equal(t, "lock check: account locked", spans[0].Tag("error.raw_message"))
+}
+
+func TestUnaryErrorServerInterceptor_LogsStack(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctx := logger.ContextWithLogger(context.Background(), logger.NewLogger(zap.New(core)))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	registry := grpcerrors.NewRegistry()
+	registry.Register(errAccountLocked, grpcerrors.Mapping{Code: codes.FailedPrecondition, Message: "Account is locked"})
+	interceptor := interceptors.NewUnaryErrorServerInterceptor(interceptors.ErrorRegistry(registry),
+		interceptors.SanitizeUnexpectedErrors(false))
+
+	for _, handlerErr := range []error{
+		crerrors.Wrap(errAccountLocked, "lock check"), // Translated
+		crerrors.New("pq: connection refused"),        // Not sanitized
+		status.Error(codes.NotFound, "not found"),     // No stack
+	} {
+		_, _ = interceptor(ctx, nil, info, func(_ context.Context, _ any) (any, error) {
+			return nil, handlerErr
+		})
+	}
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		require.Contains(t, entry.ContextMap()["error_stack"], "TestUnaryErrorServerInterceptor_LogsStack")
+	}
+}
+
+func TestUnaryErrorServerInterceptor_Sanitize(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	rawErr := errors.New("pq: relation \"wallets\" does not exist on db-1.internal")
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(headers.HeaderXRequestID, "req-42"))
+
+	tests := []struct {
+		name       string
+		opts       []interceptors.ErrorHandlerOption
+		handlerErr error
+		wantCode   codes.Code
+		wantMsg    string
+	}{
+		{
+			name:       "sanitization disabled keeps raw error",
+			opts:       []interceptors.ErrorHandlerOption{interceptors.SanitizeUnexpectedErrors(false)},
+			handlerErr: rawErr,
+			wantCode:   codes.Unknown,
+			wantMsg:    rawErr.Error(),
+		},
+		{
+			name:       "non status error is sanitized",
+			opts:       []interceptors.ErrorHandlerOption{interceptors.SanitizeUnexpectedErrors(true)},
+			handlerErr: rawErr,
+			wantCode:   codes.Internal,
+			wantMsg:    "Internal server error (reference: req-42)",
+		},
+		{
+			name:       "client cancellation keeps its code",
+			opts:       []interceptors.ErrorHandlerOption{interceptors.SanitizeUnexpectedErrors(true)},
+			handlerErr: fmt.Errorf("query wallets: %w", context.Canceled),
+			wantCode:   codes.Canceled,
+			wantMsg:    "context canceled",
+		},
+		{
+			name:       "deadline keeps its code",
+			opts:       []interceptors.ErrorHandlerOption{interceptors.SanitizeUnexpectedErrors(true)},
+			handlerErr: fmt.Errorf("query wallets: %w", context.DeadlineExceeded),
+			wantCode:   codes.DeadlineExceeded,
+			wantMsg:    "deadline exceeded",
+		},
+		{
+			name:       "cancellation keeps its message without sanitization",
+			opts:       []interceptors.ErrorHandlerOption{interceptors.SanitizeUnexpectedErrors(false)},
+			handlerErr: fmt.Errorf("query wallets: %w", context.Canceled),
+			wantCode:   codes.Canceled,
+			wantMsg:    "query wallets: context canceled",
+		},
+		{
+			name:       "status error is not sanitized",
+			opts:       []interceptors.ErrorHandlerOption{interceptors.SanitizeUnexpectedErrors(true)},
+			handlerErr: status.Error(codes.InvalidArgument, "bad amount"),
+			wantCode:   codes.InvalidArgument,
+			wantMsg:    "bad amount",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := interceptors.NewUnaryErrorServerInterceptor(tt.opts...)
+			_, err := interceptor(ctx, nil, info, func(_ context.Context, _ any) (any, error) {
+				return nil, tt.handlerErr
+			})
+			st := status.Convert(err)
+			require.Equal(t, tt.wantCode, st.Code())
+			require.Equal(t, tt.wantMsg, st.Message())
+		})
+	}
+}
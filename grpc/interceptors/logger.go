@@ -37,6 +37,12 @@ const (
 	grpcMessageKey    = "grpc_message"
 	grpcErrDetailsKey = "grpc_error_details"
 
+	// Downstream error information, logged by client interceptors
+	downstreamInternalErrorCodeKey = "downstream_internal_error_code"
+	downstreamErrorTypeKey         = "downstream_error_type"
+	downstreamErrorMessageKey      = "downstream_error_message"
+	downstreamErrorMetadataKey     = "downstream_error_metadata"
+
 	// Request/response payloads
 	requestKey  = "request"
 	responseKey = "response"
@@ -104,6 +110,9 @@ func logWithContext(
 
 	// Add gRPC status and error information
 	logFields = append(logFields, buildStatusLogFields(config, err)...)
+	if config.logDownstreamErrors {
+		logFields = append(logFields, buildDownstreamErrorLogFields(err)...)
+	}
 
 	// Add client and trace information from metadata
 	logFields = append(logFields, buildMetadataLogFields(ctx)...)
@@ -220,6 +229,27 @@ func buildStatusLogFields(config *LoggingInterceptorConfig, err error) []logger.
 	return fields
 }
 
+// buildDownstreamErrorLogFields extracts the BackendServiceError returned by a downstream service, if any.
+func buildDownstreamErrorLogFields(err error) []logger.Field {
+	if err == nil {
+		return nil
+	}
+	backendErr, parseErr := errors.ParseBackendServiceError(err)
+	if parseErr != nil {
+		return nil
+	}
+
+	fields := []logger.Field{
+		logger.Int32(downstreamInternalErrorCodeKey, backendErr.GetPublic().GetInternalErrorCode()),
+		logger.String(downstreamErrorTypeKey, backendErr.GetPrivate().GetErrorType()),
+		logger.String(downstreamErrorMessageKey, backendErr.GetPrivate().GetMessage()),
+	}
+	if metadata := backendErr.GetPrivate().GetMetadata(); len(metadata) > 0 {
+		fields = append(fields, logger.Any(downstreamErrorMetadataKey, metadata))
+	}
+	return fields
+}
+
 // buildMetadataLogFields extracts client and trace information from gRPC metadata
 func buildMetadataLogFields(ctx context.Context) []logger.Field {
 	var fields []logger.Field
@@ -17,9 +17,11 @@ import (
 // - gRPC method and service names
 // - Client ID and trace information
 // - Error details and status codes
+// - The BackendServiceError returned by the downstream service, if any
 func UnaryLoggerClientInterceptor(log *logger.Logger, opts ...LoggingInterceptorOption) grpc.UnaryClientInterceptor {
 	// Build configuration from provided options
 	config := interceptorConfig(opts...)
+	config.logDownstreamErrors = true
 
 	return func(
 		ctx context.Context,
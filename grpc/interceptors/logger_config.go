@@ -31,6 +31,9 @@ type LoggingInterceptorConfig struct {
 
 	// skip logging by environment and code
 	skipLoggingByEnvAndCode map[string]map[codes.Code]struct{}
+
+	// log the BackendServiceError returned by downstream services, set by client interceptors
+	logDownstreamErrors bool
 }
 
 type LoggingInterceptorOption func(*LoggingInterceptorConfig)
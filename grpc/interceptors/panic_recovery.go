@@ -40,7 +40,7 @@ func UnaryPanicRecoveryServerInterceptor(logger *logger.Logger) grpc.UnaryServer
 				span.SetTag(ext.Error, true)
 				span.SetTag(ext.ErrorType, "panic")
 				span.SetTag(ext.ErrorMsg, codes.Internal.String())
-				// span.SetTag(ext.ErrorStack, fmt.Sprintf("%+v", panicValue))
+				span.SetTag(ext.ErrorStack, string(debug.Stack()))
 			}
 
 			// Return sanitized error to client (don't expose internal panic details)
@@ -71,7 +71,7 @@ func StreamPanicRecoveryServerInterceptor(logger *logger.Logger) grpc.StreamServ
 				span.SetTag(ext.Error, true)
 				span.SetTag(ext.ErrorType, "panic")
 				span.SetTag(ext.ErrorMsg, codes.Internal.String())
-				// span.SetTag(ext.ErrorStack, fmt.Sprintf("%+v", panicValue))
+				span.SetTag(ext.ErrorStack, string(debug.Stack()))
 			}
 
 			// Return sanitized error to client (don't expose internal panic details)
@@ -57,7 +57,7 @@ func UnaryAuthUnaryInterceptor(cfg *auth.Config) grpc.UnaryServerInterceptor {
 		}
 
 		// Validate if the extracted token matches any of the allowed keys.
-		if !cfg.Keys[token] {
+		if !cfg.HasKey(token) {
 			return nil, status.Error(codes.Unauthenticated, "invalid API key provided")
 		}
 
@@ -234,6 +234,39 @@ func TestAuthUnaryInterceptor(t *testing.T) {
 			expectCalled:   true,
 			expectResponse: "success",
 		},
+		{
+			name: "Valid token in key store - proceeds to handler",
+			cfg: &auth.Config{
+				Enabled:    true,
+				HeaderName: "Authorization",
+				Scheme:     "Bearer",
+				Keys:       map[string]bool{"static-key": true},
+				KeyStore:   auth.NewKeyStore("rotated-key"),
+			},
+			fullMethod: "/service.Method",
+			md: metadata.New(map[string]string{
+				"authorization": "Bearer rotated-key",
+			}),
+			expectErr:      nil,
+			expectCalled:   true,
+			expectResponse: "success",
+		},
+		{
+			name: "Static token ignored with a key store - returns 'invalid API key provided'",
+			cfg: &auth.Config{
+				Enabled:    true,
+				HeaderName: "Authorization",
+				Scheme:     "Bearer",
+				Keys:       map[string]bool{"static-key": true},
+				KeyStore:   auth.NewKeyStore("rotated-key"),
+			},
+			fullMethod: "/service.Method",
+			md: metadata.New(map[string]string{
+				"authorization": "Bearer static-key",
+			}),
+			expectErr:    status.Error(codes.Unauthenticated, "invalid API key provided"),
+			expectCalled: false,
+		},
 		{
 			name: "Custom header and scheme - valid",
 			cfg: &auth.Config{
@@ -78,6 +78,64 @@ func (x *BackendServiceError) GetPrivate() *BackendServiceError_Private {
 	return nil
 }
 
+// TranslationKey references a localized client message.
+// It is carried in BackendServiceError.Public.details; the gateway resolves it against its
+// message bundles using the request's Accept-Language and uses it as the client message.
+type TranslationKey struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Key of the message in the bundles, e.g. "wallet.not_found".
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Named parameters substituted in the translated message, e.g. {"address": "0xabc"}
+	// for "Wallet {address} not found".
+	Params        map[string]string `protobuf:"bytes,2,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TranslationKey) Reset() {
+	*x = TranslationKey{}
+	mi := &file_error_error_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TranslationKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranslationKey) ProtoMessage() {}
+
+func (x *TranslationKey) ProtoReflect() protoreflect.Message {
+	mi := &file_error_error_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranslationKey.ProtoReflect.Descriptor instead.
+func (*TranslationKey) Descriptor() ([]byte, []int) {
+	return file_error_error_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TranslationKey) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *TranslationKey) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
 // Public contains client-safe error details.
 // This section may be propagated to clients (e.g., frontend or mobile).
 type BackendServiceError_Public struct {
@@ -97,7 +155,7 @@ type BackendServiceError_Public struct {
 
 func (x *BackendServiceError_Public) Reset() {
 	*x = BackendServiceError_Public{}
-	mi := &file_error_error_proto_msgTypes[1]
+	mi := &file_error_error_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -109,7 +167,7 @@ func (x *BackendServiceError_Public) String() string {
 func (*BackendServiceError_Public) ProtoMessage() {}
 
 func (x *BackendServiceError_Public) ProtoReflect() protoreflect.Message {
-	mi := &file_error_error_proto_msgTypes[1]
+	mi := &file_error_error_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -164,7 +222,7 @@ type BackendServiceError_Private struct {
 
 func (x *BackendServiceError_Private) Reset() {
 	*x = BackendServiceError_Private{}
-	mi := &file_error_error_proto_msgTypes[2]
+	mi := &file_error_error_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -176,7 +234,7 @@ func (x *BackendServiceError_Private) String() string {
 func (*BackendServiceError_Private) ProtoMessage() {}
 
 func (x *BackendServiceError_Private) ProtoReflect() protoreflect.Message {
-	mi := &file_error_error_proto_msgTypes[2]
+	mi := &file_error_error_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -241,6 +299,12 @@ const file_error_error_proto_rawDesc = "" +
 	"\bmetadata\x18\x04 \x03(\v20.error.BackendServiceError.Private.MetadataEntryR\bmetadata\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x98\x01\n" +
+	"\x0eTranslationKey\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x129\n" +
+	"\x06params\x18\x02 \x03(\v2!.error.TranslationKey.ParamsEntryR\x06params\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B;Z9github.com/rainbow-me/platform-tools/grpc/protos/v1/errorb\x06proto3"
 
 var (
@@ -255,24 +319,27 @@ func file_error_error_proto_rawDescGZIP() []byte {
 	return file_error_error_proto_rawDescData
 }
 
-var file_error_error_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_error_error_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_error_error_proto_goTypes = []any{
 	(*BackendServiceError)(nil),         // 0: error.BackendServiceError
-	(*BackendServiceError_Public)(nil),  // 1: error.BackendServiceError.Public
-	(*BackendServiceError_Private)(nil), // 2: error.BackendServiceError.Private
-	nil,                                 // 3: error.BackendServiceError.Private.MetadataEntry
-	(*anypb.Any)(nil),                   // 4: google.protobuf.Any
+	(*TranslationKey)(nil),              // 1: error.TranslationKey
+	(*BackendServiceError_Public)(nil),  // 2: error.BackendServiceError.Public
+	(*BackendServiceError_Private)(nil), // 3: error.BackendServiceError.Private
+	nil,                                 // 4: error.BackendServiceError.Private.MetadataEntry
+	nil,                                 // 5: error.TranslationKey.ParamsEntry
+	(*anypb.Any)(nil),                   // 6: google.protobuf.Any
 }
 var file_error_error_proto_depIdxs = []int32{
-	1, // 0: error.BackendServiceError.public:type_name -> error.BackendServiceError.Public
-	2, // 1: error.BackendServiceError.private:type_name -> error.BackendServiceError.Private
-	4, // 2: error.BackendServiceError.Public.details:type_name -> google.protobuf.Any
-	3, // 3: error.BackendServiceError.Private.metadata:type_name -> error.BackendServiceError.Private.MetadataEntry
-	4, // [4:4] is the sub-list for method output_type
-	4, // [4:4] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	2, // 0: error.BackendServiceError.public:type_name -> error.BackendServiceError.Public
+	3, // 1: error.BackendServiceError.private:type_name -> error.BackendServiceError.Private
+	5, // 2: error.TranslationKey.params:type_name -> error.TranslationKey.ParamsEntry
+	6, // 3: error.BackendServiceError.Public.details:type_name -> google.protobuf.Any
+	4, // 4: error.BackendServiceError.Private.metadata:type_name -> error.BackendServiceError.Private.MetadataEntry
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_error_error_proto_init() }
@@ -280,14 +347,14 @@ func file_error_error_proto_init() {
 	if File_error_error_proto != nil {
 		return
 	}
-	file_error_error_proto_msgTypes[1].OneofWrappers = []any{}
+	file_error_error_proto_msgTypes[2].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_error_error_proto_rawDesc), len(file_error_error_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
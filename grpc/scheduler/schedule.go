@@ -0,0 +1,224 @@
+package scheduler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Schedule computes the run times of a job
+type Schedule interface {
+	// Next returns the first run time strictly after t, or the zero time if there is none
+	Next(t time.Time) time.Time
+}
+
+// Every returns a schedule running every d, counted from the previous scheduled run so that runs do not drift
+func Every(d time.Duration) Schedule {
+	return interval(d)
+}
+
+type interval time.Duration
+
+func (i interval) Next(t time.Time) time.Time {
+	if i <= 0 {
+		return time.Time{}
+	}
+	return t.Add(time.Duration(i))
+}
+
+// Cron parses a standard 5-field cron expression, "minute hour day-of-month month day-of-week", evaluated in the
+// local time zone. Fields accept *, values, ranges (1-5), steps (*/15, 0-30/10), lists (1,15) and names (JAN, MON);
+// day-of-week 0 and 7 are Sunday. As in cron, a run matches either day field when both are restricted.
+// The descriptors @yearly, @monthly, @weekly, @daily, @hourly and "@every <duration>" are also accepted.
+func Cron(expr string) (Schedule, error) {
+	return CronIn(expr, time.Local)
+}
+
+// CronIn is Cron evaluated in the time zone loc
+func CronIn(expr string, loc *time.Location) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if rest, ok := strings.CutPrefix(expr, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+		}
+		if d <= 0 {
+			return nil, errors.Newf("invalid cron expression %q: interval must be positive", expr)
+		}
+		return Every(d), nil
+	}
+	if descriptor, ok := cronDescriptors[expr]; ok {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.Newf("invalid cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+	schedule := &cronSchedule{loc: loc}
+	var err error
+	if schedule.minute, err = parseCronField(fields[0], minuteField); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+	}
+	if schedule.hour, err = parseCronField(fields[1], hourField); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+	}
+	if schedule.dom, err = parseCronField(fields[2], domField); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+	}
+	if schedule.month, err = parseCronField(fields[3], monthField); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+	}
+	if schedule.dow, err = parseCronField(fields[4], dowField); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+	}
+	if schedule.dow.bits&(1<<7) != 0 {
+		schedule.dow.bits |= 1 // 7 is Sunday too
+	}
+	return schedule, nil
+}
+
+// MustCron is Cron panicking on invalid expressions, for schedules known at compile time
+func MustCron(expr string) Schedule {
+	schedule, err := Cron(expr)
+	if err != nil {
+		panic(err)
+	}
+	return schedule
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// maxCronSearch bounds the search of the next run, for expressions that never match such as February 30
+const maxCronSearch = 5 * 366 * 24 * time.Hour
+
+type cronSchedule struct {
+	minute, hour, dom, month, dow cronField
+	loc                           *time.Location
+}
+
+func (c *cronSchedule) Next(t time.Time) time.Time {
+	t = t.In(c.loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxCronSearch)
+
+	for t.Before(limit) {
+		year, month, day := t.Date()
+		switch {
+		case !c.month.has(int(month)):
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, c.loc)
+		case !c.dayMatches(t):
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, c.loc)
+		case !c.hour.has(t.Hour()):
+			t = time.Date(year, month, day, t.Hour()+1, 0, 0, 0, c.loc)
+		case !c.minute.has(t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches follows cron: when both day fields are restricted, either of them matches.
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch, dowMatch := c.dom.has(t.Day()), c.dow.has(int(t.Weekday()))
+	if c.dom.all || c.dow.all {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// cronField is the set of values matched by a field
+type cronField struct {
+	bits uint64
+	all  bool // Whether the field is *, which changes how day fields combine
+}
+
+func (f cronField) has(v int) bool {
+	return f.bits&(1<<uint(v)) != 0
+}
+
+type cronFieldSpec struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronFieldSpec{name: "minute", min: 0, max: 59}
+	hourField   = cronFieldSpec{name: "hour", min: 0, max: 23}
+	domField    = cronFieldSpec{name: "day of month", min: 1, max: 31}
+	monthField  = cronFieldSpec{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	dowField = cronFieldSpec{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+func parseCronField(field string, spec cronFieldSpec) (cronField, error) {
+	var result cronField
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return cronField{}, errors.Newf("invalid %s step %q", spec.name, stepPart)
+			}
+		}
+
+		low, high := spec.min, spec.max
+		switch {
+		case rangePart == "*":
+			result.all = result.all || !hasStep
+		case strings.Contains(rangePart, "-"):
+			lowPart, highPart, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = spec.value(lowPart); err != nil {
+				return cronField{}, err
+			}
+			if high, err = spec.value(highPart); err != nil {
+				return cronField{}, err
+			}
+			if low > high {
+				return cronField{}, errors.Newf("invalid %s range %q", spec.name, rangePart)
+			}
+		default:
+			var err error
+			if low, err = spec.value(rangePart); err != nil {
+				return cronField{}, err
+			}
+			if !hasStep {
+				high = low // A single value, or the start of a step such as 5/15
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			result.bits |= 1 << uint(v)
+		}
+	}
+	return result, nil
+}
+
+func (spec cronFieldSpec) value(s string) (int, error) {
+	if v, ok := spec.names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < spec.min || v > spec.max {
+		return 0, errors.Newf("invalid %s %q, expected %d-%d", spec.name, s, spec.min, spec.max)
+	}
+	return v, nil
+}
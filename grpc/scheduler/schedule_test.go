@@ -0,0 +1,67 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rainbow-me/platform-tools/grpc/scheduler"
+)
+
+func TestCron_Next(t *testing.T) {
+	// Friday
+	from := time.Date(2025, time.January, 10, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2025, time.January, 10, 10, 15, 0, 0, time.UTC)},
+		{"7 10 * * *", time.Date(2025, time.January, 11, 10, 7, 0, 0, time.UTC)},
+		{"0 9 * * MON-FRI", time.Date(2025, time.January, 13, 9, 0, 0, 0, time.UTC)},
+		{"30 2 1,15 * *", time.Date(2025, time.January, 15, 2, 30, 0, 0, time.UTC)},
+		{"0 0 1 jun *", time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, time.January, 12, 0, 0, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2025, time.January, 10, 10, 25, 0, 0, time.UTC)},
+		{"0 0 13 * 2", time.Date(2025, time.January, 13, 0, 0, 0, 0, time.UTC)}, // Either day field matches
+		{"@hourly", time.Date(2025, time.January, 10, 11, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+		{"0 0 30 2 *", time.Time{}}, // Never
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := scheduler.CronIn(tt.expr, time.UTC)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, schedule.Next(from))
+		})
+	}
+}
+
+func TestCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * FOO *",
+		"@every 1x",
+		"@every -1m",
+	} {
+		_, err := scheduler.Cron(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestEvery(t *testing.T) {
+	from := time.Date(2025, time.January, 10, 10, 7, 30, 0, time.UTC)
+	require.Equal(t, from.Add(time.Minute), scheduler.Every(time.Minute).Next(from))
+	require.True(t, scheduler.Every(0).Next(from).IsZero())
+}
@@ -0,0 +1,233 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/tracer"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+
+	"github.com/rainbow-me/platform-tools/common/correlation"
+	"github.com/rainbow-me/platform-tools/common/logger"
+	"github.com/rainbow-me/platform-tools/observability"
+)
+
+// SpanName is the operation name of the span created for every run
+const SpanName = "scheduler.job"
+
+// ErrJobTimeout is returned by runs exceeding the timeout of their job
+var ErrJobTimeout = errors.New("scheduled job timed out")
+
+// Job is a function run on a schedule
+type Job struct {
+	Name          string                      // Unique name, used in logs and as the span resource
+	Schedule      Schedule                    // When to run, see Every and Cron
+	Run           func(context.Context) error // Receives a context with a new span and correlation ID
+	Timeout       time.Duration               // Maximum duration of a run, none if zero
+	Jitter        time.Duration               // Random delay up to Jitter added to every run, to spread the load
+	SkipIfRunning bool                        // Skip a run while the previous one has not returned, instead of overlapping
+}
+
+// Clock tells the time and waits, replaced in tests to control the schedules
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Option is a functional option for configuring a Scheduler
+type Option func(*Scheduler)
+
+// WithLogger sets the logger of the runs, with the job name, trace and correlation ID as fields
+func WithLogger(l *logger.Logger) Option {
+	return func(s *Scheduler) {
+		s.logger = l
+	}
+}
+
+// WithClock replaces the system clock deciding when jobs run. Job timeouts always use the system clock.
+func WithClock(clock Clock) Option {
+	return func(s *Scheduler) {
+		s.clock = clock
+	}
+}
+
+// Scheduler runs jobs on their schedules, see Run
+type Scheduler struct {
+	logger *logger.Logger
+	clock  Clock
+
+	mu      sync.Mutex
+	jobs    []*jobState
+	started bool
+	runs    sync.WaitGroup // Tracks the running jobs
+}
+
+type jobState struct {
+	Job
+	running atomic.Int32 // Number of runs in progress
+}
+
+// New creates a Scheduler without jobs, add them with Add.
+func New(opts ...Option) *Scheduler {
+	s := &Scheduler{
+		logger: logger.NoOp(),
+		clock:  systemClock{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Add registers a job, before Run is called.
+func (s *Scheduler) Add(job Job) error {
+	switch {
+	case job.Name == "":
+		return errors.New("job has no name")
+	case job.Schedule == nil:
+		return errors.Newf("job %s has no schedule", job.Name)
+	case job.Run == nil:
+		return errors.Newf("job %s has no function", job.Name)
+	case job.Timeout < 0 || job.Jitter < 0:
+		return errors.Newf("job %s has a negative timeout or jitter", job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return errors.Newf("cannot add job %s to a running scheduler", job.Name)
+	}
+	for _, existing := range s.jobs {
+		if existing.Name == job.Name {
+			return errors.Newf("duplicate job name: %s", job.Name)
+		}
+	}
+	s.jobs = append(s.jobs, &jobState{Job: job})
+	return nil
+}
+
+// Run runs the jobs on their schedules until ctx is done, then waits for the running jobs to return. Runs receive
+// a context derived from ctx, so they are cancelled at the same time. A failed or panicking run is logged and the
+// job keeps its schedule. Runs missed while the process was busy, e.g. suspended, are skipped.
+// A Scheduler runs once; use server.WithScheduler to run it with a Server.
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.started {
+		s.mu.Unlock()
+		return errors.New("scheduler already started")
+	}
+	s.started = true
+	jobs := s.jobs
+	s.mu.Unlock()
+
+	s.logger.Info("Starting scheduler", logger.Int("jobs", len(jobs)))
+
+	var loops sync.WaitGroup
+	for _, job := range jobs {
+		loops.Add(1)
+		go func() {
+			defer loops.Done()
+			s.loop(ctx, job)
+		}()
+	}
+	loops.Wait()
+	s.runs.Wait()
+
+	s.logger.Info("Scheduler stopped")
+	return nil
+}
+
+// loop starts the runs of a job until ctx is done or the schedule ends.
+func (s *Scheduler) loop(ctx context.Context, job *jobState) {
+	next := job.Schedule.Next(s.clock.Now())
+	for !next.IsZero() {
+		wait := next.Sub(s.clock.Now())
+		if job.Jitter > 0 {
+			wait += rand.N(job.Jitter)
+		}
+		select {
+		case <-s.clock.After(wait):
+		case <-ctx.Done():
+			return
+		}
+
+		s.start(ctx, job)
+
+		// Schedule from the previous run time so that intervals do not drift, skipping the missed runs
+		next = job.Schedule.Next(next)
+		if now := s.clock.Now(); !next.IsZero() && !next.After(now) {
+			next = job.Schedule.Next(now)
+		}
+	}
+	s.logger.Warn("Scheduled job has no next run", logger.String("job", job.Name))
+}
+
+// start runs the job in a goroutine, unless it should be skipped.
+func (s *Scheduler) start(ctx context.Context, job *jobState) {
+	if job.SkipIfRunning {
+		if !job.running.CompareAndSwap(0, 1) {
+			s.logger.Warn("Skipping scheduled job, the previous run has not returned", logger.String("job", job.Name))
+			return
+		}
+	} else {
+		job.running.Add(1)
+	}
+
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		defer job.running.Add(-1)
+		_ = s.execute(ctx, job)
+	}()
+}
+
+// execute runs the job once, with its own span, correlation ID and timeout, and recovers its panics.
+func (s *Scheduler) execute(ctx context.Context, job *jobState) (err error) {
+	ctx = logger.ContextWithLogger(ctx, s.logger.With(logger.String("job", job.Name)))
+	span, ctx := observability.StartSpan(ctx, SpanName, tracer.ResourceName(job.Name))
+	// A new correlation ID for every run, replacing the one of the context passed to Run if any
+	ctx = correlation.SetID(ctx, uuid.NewString())
+	ctx = logger.ContextWithFields(ctx, logger.String(correlation.IDKey, correlation.ID(ctx)))
+	log := logger.FromContext(ctx)
+
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, job.Timeout, ErrJobTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	defer func() {
+		var panicFields []logger.Field
+		if r := recover(); r != nil {
+			panicFields = logger.WithPanic(r)
+			err = errors.Newf("job %s panicked: %v", job.Name, r)
+		}
+		duration := time.Since(start)
+		span.Finish(tracer.WithError(err))
+
+		if err != nil {
+			fields := append([]logger.Field{logger.Duration("duration", duration), logger.Error(err)}, panicFields...)
+			log.Error("Scheduled job failed", fields...)
+			return
+		}
+		log.Info("Scheduled job completed", logger.Duration("duration", duration))
+	}()
+
+	log.Debug("Running scheduled job")
+	err = job.Run(ctx)
+	if err != nil && errors.Is(context.Cause(ctx), ErrJobTimeout) {
+		err = fmt.Errorf("%w after %s: %w", ErrJobTimeout, job.Timeout, err)
+	}
+	return err
+}
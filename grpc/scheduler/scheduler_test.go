@@ -0,0 +1,277 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/ext"
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/mocktracer"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/rainbow-me/platform-tools/common/correlation"
+	"github.com/rainbow-me/platform-tools/common/logger"
+	"github.com/rainbow-me/platform-tools/grpc/scheduler"
+)
+
+// fakeClock fires the timers when Advance moves the time past them.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, time.January, 10, 10, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeTimer{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the time forward once the scheduler waits for the given number of timers.
+func (c *fakeClock) Advance(t *testing.T, d time.Duration, waiters int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.waiters) == waiters
+	}, time.Second, time.Millisecond)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = pending
+}
+
+func runScheduler(t *testing.T, sched *scheduler.Scheduler) (cancel func()) {
+	t.Helper()
+	return runSchedulerWithContext(t, context.Background(), sched)
+}
+
+func runSchedulerWithContext(t *testing.T, parent context.Context, sched *scheduler.Scheduler) (cancel func()) {
+	t.Helper()
+	ctx, cancelCtx := context.WithCancel(parent)
+	done := make(chan error)
+	go func() {
+		done <- sched.Run(ctx)
+	}()
+	return func() {
+		cancelCtx()
+		require.NoError(t, <-done)
+	}
+}
+
+func TestScheduler_Run(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	clock := newFakeClock()
+	core, logs := observer.New(zapcore.InfoLevel)
+	sched := scheduler.New(scheduler.WithClock(clock), scheduler.WithLogger(logger.NewLogger(zap.New(core))))
+
+	ids := make(chan string, 10)
+	require.NoError(t, sched.Add(scheduler.Job{
+		Name:     "cleanup",
+		Schedule: scheduler.Every(time.Minute),
+		Run: func(ctx context.Context) error {
+			ids <- correlation.ID(ctx)
+			return nil
+		},
+	}))
+	require.NoError(t, sched.Add(scheduler.Job{
+		Name:     "report",
+		Schedule: scheduler.Every(2 * time.Minute),
+		Run: func(context.Context) error {
+			return errors.New("database unavailable")
+		},
+	}))
+	// Every run gets its own correlation ID, even when Run is called with one
+	stop := runSchedulerWithContext(t, correlation.SetID(context.Background(), "parent"), sched)
+
+	clock.Advance(t, 30*time.Second, 2)
+	require.Empty(t, ids)
+
+	clock.Advance(t, 30*time.Second, 2) // 1m: cleanup
+	first := <-ids
+	clock.Advance(t, time.Minute, 2) // 2m: cleanup and report
+	second := <-ids
+	require.NotEmpty(t, first)
+	require.NotEqual(t, first, second)
+	require.NotEqual(t, "parent", first)
+	require.NotEqual(t, "parent", second)
+
+	clock.Advance(t, 0, 2) // Wait for the report run to be scheduled again
+	stop()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 3)
+	var failed int
+	for _, span := range spans {
+		require.Equal(t, scheduler.SpanName, span.OperationName())
+		if span.Tag(ext.ResourceName) == "report" {
+			failed++
+			require.NotNil(t, span.Tag(ext.ErrorMsg))
+		}
+	}
+	require.Equal(t, 1, failed)
+
+	// The run logs carry the correlation ID of the run
+	var loggedIDs []any
+	for _, entry := range logs.FilterMessage("Scheduled job completed").All() {
+		loggedIDs = append(loggedIDs, entry.ContextMap()["correlation_id"])
+	}
+	require.ElementsMatch(t, []any{first, second}, loggedIDs)
+}
+
+func TestScheduler_SkipIfRunning(t *testing.T) {
+	clock := newFakeClock()
+	sched := scheduler.New(scheduler.WithClock(clock))
+
+	var runs atomic.Int32
+	release := make(chan struct{})
+	require.NoError(t, sched.Add(scheduler.Job{
+		Name:          "sync",
+		Schedule:      scheduler.Every(time.Minute),
+		SkipIfRunning: true,
+		Run: func(context.Context) error {
+			runs.Add(1)
+			<-release
+			return nil
+		},
+	}))
+	stop := runScheduler(t, sched)
+
+	clock.Advance(t, time.Minute, 1)
+	clock.Advance(t, time.Minute, 1) // Skipped, the first run is blocked
+	require.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, time.Millisecond)
+	close(release)
+
+	// Runs again once the first run returned
+	deadline := time.Now().Add(time.Second)
+	for runs.Load() < 2 && time.Now().Before(deadline) {
+		clock.Advance(t, time.Minute, 1)
+		time.Sleep(5 * time.Millisecond)
+	}
+	require.Equal(t, int32(2), runs.Load())
+	stop()
+}
+
+func TestScheduler_TimeoutAndPanic(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	clock := newFakeClock()
+	core, logs := observer.New(zapcore.InfoLevel)
+	sched := scheduler.New(scheduler.WithClock(clock), scheduler.WithLogger(logger.NewLogger(zap.New(core))))
+	require.NoError(t, sched.Add(scheduler.Job{
+		Name:     "slow",
+		Schedule: scheduler.Every(time.Minute),
+		Timeout:  10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}))
+	require.NoError(t, sched.Add(scheduler.Job{
+		Name:     "broken",
+		Schedule: scheduler.Every(time.Minute),
+		Run: func(context.Context) error {
+			panic("nil map")
+		},
+	}))
+	stop := runScheduler(t, sched)
+
+	clock.Advance(t, time.Minute, 2)
+	require.Eventually(t, func() bool { return len(mt.FinishedSpans()) == 2 }, time.Second, time.Millisecond)
+
+	// The panic does not stop the schedule
+	clock.Advance(t, time.Minute, 2)
+	require.Eventually(t, func() bool { return len(mt.FinishedSpans()) == 4 }, time.Second, time.Millisecond)
+	stop()
+
+	// A panicking run is logged once, with the panic details
+	panics := logs.FilterField(zap.String("job", "broken")).All()
+	require.Len(t, panics, 2)
+	for _, entry := range panics {
+		require.Equal(t, "Scheduled job failed", entry.Message)
+		require.Contains(t, entry.ContextMap(), logger.PanicValueKey)
+	}
+
+	for _, span := range mt.FinishedSpans() {
+		switch span.Tag(ext.ResourceName) {
+		case "slow":
+			require.Contains(t, span.Tag(ext.ErrorMsg), "scheduled job timed out after 10ms")
+		case "broken":
+			require.Contains(t, span.Tag(ext.ErrorMsg), "job broken panicked: nil map")
+		}
+	}
+}
+
+func TestScheduler_Jitter(t *testing.T) {
+	clock := newFakeClock()
+	sched := scheduler.New(scheduler.WithClock(clock))
+	ran := make(chan struct{}, 1)
+	require.NoError(t, sched.Add(scheduler.Job{
+		Name:     "jittered",
+		Schedule: scheduler.Every(time.Minute),
+		Jitter:   10 * time.Second,
+		Run: func(context.Context) error {
+			ran <- struct{}{}
+			return nil
+		},
+	}))
+	stop := runScheduler(t, sched)
+	defer stop()
+
+	clock.Advance(t, time.Minute+10*time.Second, 1)
+	<-ran
+}
+
+func TestScheduler_Add(t *testing.T) {
+	sched := scheduler.New()
+	run := func(context.Context) error { return nil }
+
+	require.ErrorContains(t, sched.Add(scheduler.Job{Schedule: scheduler.Every(time.Minute), Run: run}), "no name")
+	require.ErrorContains(t, sched.Add(scheduler.Job{Name: "a", Run: run}), "no schedule")
+	require.ErrorContains(t, sched.Add(scheduler.Job{Name: "a", Schedule: scheduler.Every(time.Minute)}), "no function")
+	require.NoError(t, sched.Add(scheduler.Job{Name: "a", Schedule: scheduler.Every(time.Minute), Run: run}))
+	require.ErrorContains(t, sched.Add(scheduler.Job{Name: "a", Schedule: scheduler.Every(time.Minute), Run: run}),
+		"duplicate job name: a")
+
+	stop := runScheduler(t, sched)
+	require.Eventually(t, func() bool {
+		return sched.Add(scheduler.Job{Name: "b", Schedule: scheduler.Every(time.Minute), Run: run}) != nil
+	}, time.Second, time.Millisecond)
+	stop()
+	require.ErrorContains(t, sched.Run(context.Background()), "already started")
+}
@@ -0,0 +1,396 @@
+package server
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/rainbow-me/platform-tools/common/logger"
+	"github.com/rainbow-me/platform-tools/grpc/auth"
+	"github.com/rainbow-me/platform-tools/grpc/interceptors"
+)
+
+// AdminServerName is the name of the HTTP server added by WithAdminServer
+const AdminServerName = "admin"
+
+// Admin endpoints served by WithAdminServer
+const (
+	AdminPprofPath        = "/debug/pprof/"
+	AdminVarsPath         = "/debug/vars"
+	AdminBuildInfoPath    = "/debug/buildinfo"
+	AdminServicesPath     = "/debug/services"
+	AdminInterceptorsPath = "/debug/interceptors"
+	AdminConfigPath       = "/debug/config"
+	AdminLogLevelPath     = "/debug/loglevel"
+	AdminRestartsPath     = "/debug/restarts"
+	AdminStatusPath       = "/debug/status"
+)
+
+// AdminOption is a functional option for configuring the admin server
+type AdminOption func(*adminConfig)
+
+type adminConfig struct {
+	auth         *auth.Config
+	version      string
+	commit       string
+	appConfig    any
+	interceptors map[string]interceptorChains
+	httpOpts     []HTTPConfigOption
+}
+
+type interceptorChains struct {
+	unary  *interceptors.UnaryServerInterceptorChain
+	stream *interceptors.StreamServerInterceptorChain
+}
+
+// WithAdminAuth requires the API keys of auth on every admin endpoint, in the HTTP header and scheme it configures
+func WithAdminAuth(cfg *auth.Config) AdminOption {
+	return func(c *adminConfig) {
+		c.auth = cfg
+	}
+}
+
+// WithAdminBuildInfo sets the version and commit reported by the build info endpoint, e.g. from ldflags.
+// By default they come from the VCS information embedded by the Go toolchain.
+func WithAdminBuildInfo(version, commit string) AdminOption {
+	return func(c *adminConfig) {
+		c.version = version
+		c.commit = commit
+	}
+}
+
+// WithAdminConfig reports the application configuration on the config endpoint. Fields tagged redact:"true",
+// or whose name suggests a secret (password, secret, token, API key...), are redacted.
+func WithAdminConfig(config any) AdminOption {
+	return func(c *adminConfig) {
+		c.appConfig = config
+	}
+}
+
+// WithAdminInterceptors reports the order of the interceptor chains of the named gRPC server.
+// Either chain may be nil.
+func WithAdminInterceptors(
+	grpcName string,
+	unary *interceptors.UnaryServerInterceptorChain,
+	stream *interceptors.StreamServerInterceptorChain,
+) AdminOption {
+	return func(c *adminConfig) {
+		c.interceptors[grpcName] = interceptorChains{unary: unary, stream: stream}
+	}
+}
+
+// WithAdminHTTPOptions configures the admin HTTP server, e.g. WithHTTPNetwork to serve it on a unix socket.
+// The admin server is optional by default: it is restarted when it fails, and never stops the Server.
+func WithAdminHTTPOptions(opts ...HTTPConfigOption) AdminOption {
+	return func(c *adminConfig) {
+		c.httpOpts = append(c.httpOpts, opts...)
+	}
+}
+
+// WithAdminServer adds an HTTP server named AdminServerName exposing debug endpoints: pprof, expvar, build info,
+// registered gRPC services and methods, interceptor order, the redacted configuration, the log level, and the
+// status and restarts of the servers.
+// Keep port private: pprof and the configuration must not be reachable from the public network.
+func WithAdminServer(port string, opts ...AdminOption) Option {
+	return func(s *Server) error {
+		config := &adminConfig{interceptors: make(map[string]interceptorChains)}
+		for _, opt := range opts {
+			opt(config)
+		}
+
+		s.adminMux = http.NewServeMux()
+		s.adminMux.HandleFunc(AdminPprofPath, pprof.Index)
+		s.adminMux.HandleFunc(AdminPprofPath+"cmdline", pprof.Cmdline)
+		s.adminMux.HandleFunc(AdminPprofPath+"profile", pprof.Profile)
+		s.adminMux.HandleFunc(AdminPprofPath+"symbol", pprof.Symbol)
+		s.adminMux.HandleFunc(AdminPprofPath+"trace", pprof.Trace)
+		s.adminMux.Handle(AdminVarsPath, expvar.Handler())
+		s.adminMux.HandleFunc(AdminBuildInfoPath, func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, readBuildInfo(config.version, config.commit))
+		})
+		s.adminMux.HandleFunc(AdminServicesPath, func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, s.services())
+		})
+		s.adminMux.HandleFunc(AdminInterceptorsPath, func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, interceptorOrder(config.interceptors))
+		})
+		s.adminMux.Handle(AdminLogLevelPath, logger.LevelHandler())
+		s.adminMux.HandleFunc(AdminStatusPath, func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, serverStatusViews(s.Status()))
+		})
+		s.adminMux.HandleFunc(AdminRestartsPath, func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, restartEventViews(s.RestartEvents()))
+		})
+		s.adminMux.HandleFunc(AdminConfigPath, func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, map[string]any{
+				"server":      s.effectiveConfig(),
+				"application": redactConfig(config.appConfig),
+			})
+		})
+
+		// A failing debug listener must not take the API down
+		httpOpts := append([]HTTPConfigOption{WithHTTPRestartPolicy(RestartPolicy{Mode: RestartOnFailure, Optional: true})},
+			config.httpOpts...)
+		httpOpts = append(httpOpts, func(c *HTTPConfig) {
+			if c.Listener != nil {
+				c.Restart.Mode = RestartNever // A pre-built listener cannot be reopened
+			}
+		})
+		return WithHTTPServer(AdminServerName, port, requireAPIKey(config.auth, s.adminMux), httpOpts...)(s)
+	}
+}
+
+// BuildInfo is reported by the build info endpoint
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	CommitAt  string `json:"commit_time,omitempty"`
+	Modified  bool   `json:"modified"`
+	GoVersion string `json:"go_version"`
+	Path      string `json:"path"`
+}
+
+func readBuildInfo(version, commit string) BuildInfo {
+	info := BuildInfo{Version: version, Commit: commit, GoVersion: runtime.Version()}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Path = build.Main.Path
+	if info.Version == "" {
+		info.Version = build.Main.Version
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			info.CommitAt = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// services returns the methods of the registered services, by gRPC server and service name.
+func (s *Server) services() map[string]map[string][]string {
+	s.serverMu.RLock()
+	defer s.serverMu.RUnlock()
+
+	result := make(map[string]map[string][]string, len(s.grpcServers)+len(s.multiplexed))
+	add := func(name string, info map[string]grpc.ServiceInfo) {
+		services := make(map[string][]string, len(info))
+		for service, serviceInfo := range info {
+			methods := make([]string, 0, len(serviceInfo.Methods))
+			for _, method := range serviceInfo.Methods {
+				methods = append(methods, method.Name)
+			}
+			slices.Sort(methods)
+			services[service] = methods
+		}
+		result[name] = services
+	}
+	for name, server := range s.grpcServers {
+		add(name, server.GetServiceInfo())
+	}
+	for name, mux := range s.multiplexed {
+		add(name, mux.grpc.GetServiceInfo())
+	}
+	return result
+}
+
+// serverStatusView is the JSON representation of a ServerStatus
+type serverStatusView struct {
+	Name     string      `json:"name"`
+	Kind     string      `json:"kind"`
+	State    ServerState `json:"state"`
+	Since    time.Time   `json:"since"`
+	Address  string      `json:"address,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Restarts int         `json:"restarts"`
+
+	Connections int64 `json:"connections"`
+	Rejected    int64 `json:"rejected_connections"`
+}
+
+func serverStatusViews(statuses []ServerStatus) []serverStatusView {
+	views := make([]serverStatusView, 0, len(statuses))
+	for _, status := range statuses {
+		view := serverStatusView{Name: status.Name, Kind: status.Kind, State: status.State, Since: status.Since,
+			Restarts: status.Restarts, Connections: status.Connections, Rejected: status.Rejected}
+		if status.Addr != nil {
+			view.Address = status.Addr.String()
+		}
+		if status.Err != nil {
+			view.Error = status.Err.Error()
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// restartEventView is the JSON representation of a RestartEvent
+type restartEventView struct {
+	Server  string    `json:"server"`
+	Time    time.Time `json:"time"`
+	Error   string    `json:"error"`
+	Restart int       `json:"restart,omitempty"`
+	Backoff string    `json:"backoff,omitempty"`
+	GaveUp  bool      `json:"gave_up"`
+}
+
+func restartEventViews(events []RestartEvent) []restartEventView {
+	views := make([]restartEventView, 0, len(events))
+	for _, event := range events {
+		view := restartEventView{Server: event.Server, Time: event.Time, Restart: event.Restart, GaveUp: event.GaveUp}
+		if event.Err != nil {
+			view.Error = event.Err.Error()
+		}
+		if event.Backoff > 0 {
+			view.Backoff = event.Backoff.String()
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+func interceptorOrder(chains map[string]interceptorChains) map[string]map[string][]string {
+	result := make(map[string]map[string][]string, len(chains))
+	for name, chain := range chains {
+		order := map[string][]string{"unary": {}, "stream": {}}
+		if chain.unary != nil {
+			order["unary"] = slices.Clone(chain.unary.ItemOrder)
+		}
+		if chain.stream != nil {
+			order["stream"] = slices.Clone(chain.stream.ItemOrder)
+		}
+		result[name] = order
+	}
+	return result
+}
+
+// effectiveConfig returns the configuration of the Server and its servers, after defaults.
+func (s *Server) effectiveConfig() map[string]any {
+	httpServers := make([]map[string]any, 0, len(s.httpConfigs))
+	for _, config := range s.httpConfigs {
+		httpServers = append(httpServers, map[string]any{
+			"name":             config.Name,
+			"address":          config.Address,
+			"network":          networkOrDefault(config.Network),
+			"tls":              config.TLS != nil,
+			"health_probes":    config.HealthProbes,
+			"multiplexed":      config.GRPC != nil,
+			"read_timeout":     config.ReadTimeout.String(),
+			"write_timeout":    config.WriteTimeout.String(),
+			"idle_timeout":     config.IdleTimeout.String(),
+			"header_timeout":   config.HeaderTimeout.String(),
+			"restart":          restartPolicyView(config.Restart),
+			"max_connections":  config.MaxConnections,
+			"max_header_bytes": config.MaxHeaderBytes,
+			"max_body_bytes":   config.MaxBodyBytes,
+		})
+	}
+	grpcServers := make([]map[string]any, 0, len(s.grpcConfigs))
+	for _, config := range s.grpcConfigs {
+		_, inProcess := s.inProcess[config.Name]
+		grpcServers = append(grpcServers, map[string]any{
+			"name":                   config.Name,
+			"address":                config.Address,
+			"network":                networkOrDefault(config.Network),
+			"tls":                    config.TLS != nil,
+			"in_process":             inProcess,
+			"restart":                restartPolicyView(config.Restart),
+			"max_connections":        config.MaxConnections,
+			"max_concurrent_streams": config.MaxConcurrentStreams,
+		})
+	}
+	workers := make([]map[string]any, 0, len(s.workerConfigs))
+	for _, config := range s.workerConfigs {
+		workers = append(workers, map[string]any{
+			"name":    config.Name,
+			"restart": restartPolicyView(config.Restart),
+		})
+	}
+	shutdownHooks := make([]string, 0, len(s.shutdownHooks))
+	for _, hook := range s.shutdownHooks {
+		shutdownHooks = append(shutdownHooks, hook.Name)
+	}
+	startupHooks := make([]string, 0, len(s.startupHooks))
+	for _, hook := range s.startupHooks {
+		startupHooks = append(startupHooks, hook.Name)
+	}
+	reloadHooks := make([]string, 0, len(s.reloadHooks))
+	for _, hook := range s.reloadHooks {
+		reloadHooks = append(reloadHooks, hook.Name)
+	}
+
+	return map[string]any{
+		"shutdown_timeout":    s.shutdownTimeout.String(),
+		"drain_delay":         s.drainDelay.String(),
+		"signal_handling":     s.signalHandling,
+		"automatic_stop":      s.isAutomaticStop,
+		"grpc_health_service": s.grpcHealthService,
+		"http_servers":        httpServers,
+		"grpc_servers":        grpcServers,
+		"workers":             workers,
+		"shutdown_hooks":      shutdownHooks,
+		"startup_hooks":       startupHooks,
+		"reload_hooks":        reloadHooks,
+	}
+}
+
+func restartPolicyView(policy RestartPolicy) map[string]any {
+	mode := "never"
+	if policy.Mode == RestartOnFailure {
+		mode = "on_failure"
+	}
+	return map[string]any{
+		"mode":            mode,
+		"max_restarts":    policy.MaxRestarts,
+		"initial_backoff": policy.backoff(0).String(),
+		"optional":        policy.Optional,
+	}
+}
+
+func networkOrDefault(network string) string {
+	if network == "" {
+		return NetworkTCP
+	}
+	return network
+}
+
+// requireAPIKey checks the API key of the requests when cfg is enabled, like the gRPC auth interceptor.
+func requireAPIKey(cfg *auth.Config, next http.Handler) http.Handler {
+	if cfg == nil || !cfg.Enabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, token, found := strings.Cut(strings.TrimSpace(r.Header.Get(cfg.HeaderName)), " ")
+		token = strings.TrimSpace(token)
+		if !found || scheme != cfg.Scheme || token == "" || !cfg.HasKey(token) {
+			w.Header().Set("WWW-Authenticate", cfg.Scheme)
+			http.Error(w, "invalid API key", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(v) // Fails only when the client is gone
+}
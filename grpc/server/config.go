@@ -1,39 +1,72 @@
 package server
 
 import (
+	"net"
 	"net/http"
 	"time"
 
 	"google.golang.org/grpc"
+
+	"github.com/rainbow-me/platform-tools/grpc/tlsconfig"
+)
+
+// Networks supported by HTTPConfig.Network and GRPCConfig.Network
+const (
+	NetworkTCP  = "tcp"
+	NetworkUnix = "unix"
 )
 
+// inProcessBufferSize is the buffer size of the in-memory connections between gateways and gRPC servers
+const inProcessBufferSize = 1 << 20
+
 var (
-	DefaultShutdownTimeout   = 30 * time.Second
-	DefaultHookTimeout       = 5 * time.Second
-	DefaultHTTPReadTimeout   = 5 * time.Second
-	DefaultHTTPWriteTimeout  = 10 * time.Second
-	DefaultHTTPIdleTimeout   = 120 * time.Second
-	DefaultHTTPHeaderTimeout = 2 * time.Second
+	DefaultShutdownTimeout    = 30 * time.Second
+	DefaultHookTimeout        = 5 * time.Second
+	DefaultStartupHookTimeout = 30 * time.Second
+	DefaultReloadHookTimeout  = 10 * time.Second
+	DefaultHTTPReadTimeout    = 5 * time.Second
+	DefaultHTTPWriteTimeout   = 10 * time.Second
+	DefaultHTTPIdleTimeout    = 120 * time.Second
+	DefaultHTTPHeaderTimeout  = 2 * time.Second
 )
 
 // HTTPConfig holds configuration for HTTP servers
 type HTTPConfig struct {
 	Name          string        // Unique name for this server (used in logging)
-	Address       string        // Address to bind to (e.g., ":8080")
+	Address       string        // Address to bind to (e.g., ":8080", ":0" for a random port, or a unix socket path)
+	Network       string        // Network to listen on: NetworkTCP (default) or NetworkUnix
+	Listener      net.Listener  // Pre-built listener; when set, Address and Network are ignored
 	Handler       http.Handler  // HTTP handler for this server (pre-configured with routes, middlewares, gateways, etc.)
 	ReadTimeout   time.Duration // Maximum duration for reading the entire request
 	WriteTimeout  time.Duration // Maximum duration before timing out writes
 	IdleTimeout   time.Duration // Maximum amount of time to wait for next request when keep-alives are enabled
 	HeaderTimeout time.Duration // Amount of time allowed to read request headers
+	HealthProbes  bool          // Whether to serve the /healthz and /readyz probes in front of Handler
+	Restart       RestartPolicy // Whether and how to restart the server when it fails; never by default
+
+	MaxConnections int   // Maximum open connections, unlimited if zero; requests of the others get 503 and close them
+	MaxHeaderBytes int   // Maximum size of the request headers, http.DefaultMaxHeaderBytes if zero; larger get 431
+	MaxBodyBytes   int64 // Maximum size of the request bodies, unlimited if zero; larger get 413
+
+	TLS  *tlsconfig.Certificates // Serve HTTPS with this material (reloaded on change); nil serves plain HTTP
+	GRPC *GRPCConfig             // gRPC server multiplexed on the same listener, see WithMultiplexedGRPC
 }
 
 // GRPCConfig holds configuration for gRPC servers
 type GRPCConfig struct {
 	Name       string              // Unique name for this server (used in logging)
-	Address    string              // Address to bind to (e.g., ":9090")
+	Address    string              // Address to bind to (e.g., ":9090", ":0" for a random port, or a unix socket path)
+	Network    string              // Network to listen on: NetworkTCP (default) or NetworkUnix
+	Listener   net.Listener        // Pre-built listener; when set, Address and Network are ignored
 	GRPCServer *grpc.Server        // Existing gRPC server instance; if not provided, one will be created
 	SetupFunc  func(*grpc.Server)  // Function to register services and configure the gRPC server
 	GRPCOpts   []grpc.ServerOption // Server options for creating gRPC server if GRPCServer is nil
+	Restart    RestartPolicy       // Whether and how to restart the server when it fails; requires GRPCServer to be nil
+
+	MaxConnections       int    // Maximum open connections, unlimited if zero; the others are closed (Unavailable)
+	MaxConcurrentStreams uint32 // Maximum concurrent streams per connection, see grpc.MaxConcurrentStreams
+
+	TLS *tlsconfig.Certificates // Serve TLS with this material (reloaded on change); requires GRPCServer to be nil
 }
 
 // HTTPConfigOption is a functional option for configuring HTTPConfig
@@ -66,3 +99,62 @@ func WithHTTPHeaderTimeout(timeout time.Duration) HTTPConfigOption {
 		c.HeaderTimeout = timeout
 	}
 }
+
+// WithHTTPHealthProbes serves the /healthz (liveness) and /readyz (readiness) probes of the Server on this HTTP server
+func WithHTTPHealthProbes() HTTPConfigOption {
+	return func(c *HTTPConfig) {
+		c.HealthProbes = true
+	}
+}
+
+// WithHTTPNetwork sets the network of the HTTP config, e.g. NetworkUnix to listen on the unix socket path given as port
+func WithHTTPNetwork(network string) HTTPConfigOption {
+	return func(c *HTTPConfig) {
+		c.Network = network
+	}
+}
+
+// WithHTTPListener serves the HTTP config on a pre-built listener instead of binding its address
+func WithHTTPListener(lis net.Listener) HTTPConfigOption {
+	return func(c *HTTPConfig) {
+		c.Listener = lis
+	}
+}
+
+// WithHTTPTLS serves the HTTP config over TLS, or mTLS when the certificates have a CA bundle
+func WithHTTPTLS(certs *tlsconfig.Certificates) HTTPConfigOption {
+	return func(c *HTTPConfig) {
+		c.TLS = certs
+	}
+}
+
+// WithHTTPMaxConnections limits the open connections of the HTTP server. Connections over the limit are accepted
+// and their requests answered 503 before closing them, rather than queuing in the accept backlog.
+func WithHTTPMaxConnections(maxConnections int) HTTPConfigOption {
+	return func(c *HTTPConfig) {
+		c.MaxConnections = maxConnections
+	}
+}
+
+// WithHTTPMaxHeaderBytes limits the size of the request headers of the HTTP config
+func WithHTTPMaxHeaderBytes(maxBytes int) HTTPConfigOption {
+	return func(c *HTTPConfig) {
+		c.MaxHeaderBytes = maxBytes
+	}
+}
+
+// WithHTTPMaxBodyBytes limits the size of the request bodies of the HTTP config. Requests declaring a larger
+// Content-Length get 413; reading more from a chunked body fails with an *http.MaxBytesError.
+func WithHTTPMaxBodyBytes(maxBytes int64) HTTPConfigOption {
+	return func(c *HTTPConfig) {
+		c.MaxBodyBytes = maxBytes
+	}
+}
+
+// WithHTTPRestartPolicy restarts the HTTP server according to policy when it fails to listen or serve.
+// A multiplexed gRPC server is restarted with it.
+func WithHTTPRestartPolicy(policy RestartPolicy) HTTPConfigOption {
+	return func(c *HTTPConfig) {
+		c.Restart = policy
+	}
+}
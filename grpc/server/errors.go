@@ -2,4 +2,8 @@ package server
 
 import "errors"
 
-var ErrShutdownTimeout = errors.New("shutdown timed out")
+var (
+	ErrShutdownTimeout = errors.New("shutdown timed out")
+	ErrHookTimeout     = errors.New("hook timed out")
+	ErrNotReady        = errors.New("server stopped or failed before every listener was ready")
+)
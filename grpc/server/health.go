@@ -0,0 +1,151 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+// Health tracks the serving status of the Server, reported by the standard grpc_health_v1 service registered
+// on every gRPC server and by the /healthz and /readyz HTTP probes.
+//
+// The overall status (service "") is NOT_SERVING until every listener is up, SERVING afterwards, and NOT_SERVING
+// again as soon as shutdown starts. Individual services default to SERVING once the Server is ready and can be
+// flipped with SetServingStatus; while the Server is not ready, every service reports NOT_SERVING.
+type Health struct {
+	server *grpchealth.Server
+
+	mu           sync.Mutex
+	ready        bool                                                        // every listener is up
+	shuttingDown bool                                                        // shutdown has started
+	services     map[string]grpc_health_v1.HealthCheckResponse_ServingStatus // status requested per service
+}
+
+func newHealth() *Health {
+	h := &Health{
+		server:   grpchealth.NewServer(),
+		services: make(map[string]grpc_health_v1.HealthCheckResponse_ServingStatus),
+	}
+	h.server.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	return h
+}
+
+// SetServingStatus sets the status reported for the given gRPC service, e.g. "wallet.v1.WalletService".
+// It takes effect once the Server is ready and is ignored after shutdown has started.
+func (h *Health) SetServingStatus(service string, serving bool) {
+	status := grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = grpc_health_v1.HealthCheckResponse_SERVING
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.services[service] = status
+	if h.ready && !h.shuttingDown {
+		h.server.SetServingStatus(service, status)
+	}
+}
+
+// IsServing reports whether the given service, "" for the whole Server, is SERVING.
+func (h *Health) IsServing(service string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.ready || h.shuttingDown {
+		return false
+	}
+	if service == "" {
+		return true
+	}
+	status, ok := h.services[service]
+	return ok && status == grpc_health_v1.HealthCheckResponse_SERVING
+}
+
+// LivenessHandler returns the /healthz handler: 200 as long as the process is able to serve requests,
+// including while draining, so that the orchestrator does not restart a pod that is shutting down.
+func (h *Health) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeProbe(w, true)
+	})
+}
+
+// ReadinessHandler returns the /readyz handler: 200 when the Server is SERVING, 503 otherwise.
+// The optional "service" query parameter checks an individual gRPC service instead.
+func (h *Health) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeProbe(w, h.IsServing(r.URL.Query().Get("service")))
+	})
+}
+
+func writeProbe(w http.ResponseWriter, ok bool) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(grpc_health_v1.HealthCheckResponse_NOT_SERVING.String()))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(grpc_health_v1.HealthCheckResponse_SERVING.String()))
+}
+
+// register adds the grpc_health_v1 service to srv, unless already registered, and defaults the services
+// registered on srv to SERVING.
+func (h *Health) register(srv *grpc.Server) {
+	info := srv.GetServiceInfo()
+	if _, exists := info[grpc_health_v1.Health_ServiceDesc.ServiceName]; !exists {
+		grpc_health_v1.RegisterHealthServer(srv, h.server)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for service := range info {
+		if service == grpc_health_v1.Health_ServiceDesc.ServiceName {
+			continue
+		}
+		if _, set := h.services[service]; !set {
+			h.services[service] = grpc_health_v1.HealthCheckResponse_SERVING
+		}
+		if !h.ready {
+			h.server.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		}
+	}
+}
+
+// markReady switches the Server and its services to their requested status once every listener is up.
+func (h *Health) markReady() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.shuttingDown {
+		return
+	}
+	h.ready = true
+	for service, status := range h.services {
+		h.server.SetServingStatus(service, status)
+	}
+	h.server.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+}
+
+// markShuttingDown switches every service to NOT_SERVING and ignores later updates.
+func (h *Health) markShuttingDown() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.shuttingDown = true
+	h.server.Shutdown()
+}
+
+// withProbes serves the liveness and readiness probes in front of handler.
+func (h *Health) withProbes(handler http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(LivenessPath, h.LivenessHandler())
+	mux.Handle(ReadinessPath, h.ReadinessHandler())
+	mux.Handle("/", handler)
+	return mux
+}
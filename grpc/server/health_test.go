@@ -0,0 +1,117 @@
+package server_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/rainbow-me/platform-tools/grpc/protos/gen/go/test"
+	"github.com/rainbow-me/platform-tools/grpc/server"
+)
+
+type helloServer struct {
+	test.UnimplementedHelloServiceServer
+}
+
+func TestServer_Health(t *testing.T) {
+	srv, err := server.NewServer(
+		server.WithGRPCServer("test-grpc", ":0", nil, func(s *grpc.Server) {
+			test.RegisterHelloServiceServer(s, &helloServer{})
+		}),
+		server.WithHTTPServer("test-http", ":0", http.NewServeMux(), server.WithHTTPHealthProbes()),
+		server.WithAutomaticStop(false),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	health := srv.Health()
+	require.False(t, health.IsServing(""))
+	requireProbe(t, health.ReadinessHandler(), http.StatusServiceUnavailable)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+
+	require.Eventually(t, func() bool { return health.IsServing("") }, time.Second, 10*time.Millisecond)
+	requireProbe(t, health.ReadinessHandler(), http.StatusOK)
+
+	service := test.HelloService_ServiceDesc.ServiceName
+	require.True(t, health.IsServing(service))
+	health.SetServingStatus(service, false)
+	require.False(t, health.IsServing(service))
+	require.True(t, health.IsServing(""))
+
+	require.NoError(t, srv.GracefulShutdown(context.Background()))
+	require.False(t, health.IsServing(""))
+	requireProbe(t, health.ReadinessHandler(), http.StatusServiceUnavailable)
+	requireProbe(t, health.LivenessHandler(), http.StatusOK)
+	require.NoError(t, <-done)
+}
+
+func requireProbe(t *testing.T, handler http.Handler, wantStatus int) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, server.ReadinessPath, nil))
+	require.Equal(t, wantStatus, w.Code)
+}
+
+func TestServer_DrainDelay(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ping", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	drainDelay := 300 * time.Millisecond
+	srv, err := server.NewServer(
+		server.WithHTTPServer("test-http", ":0", mux, server.WithHTTPHealthProbes()),
+		server.WithGRPCServer("test-grpc", ":0", nil, func(_ *grpc.Server) {}),
+		server.WithAutomaticStop(false),
+		server.WithSignalHandling(false),
+		server.WithDrainDelay(drainDelay),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+	require.NoError(t, srv.WaitReady(context.Background()))
+	url := "http://" + srv.Addr("test-http").String()
+
+	start := time.Now()
+	shutdownDone := make(chan error)
+	go func() {
+		shutdownDone <- srv.GracefulShutdown(context.Background())
+	}()
+	require.Eventually(t, func() bool { return srv.Status()[0].State == server.StateDraining }, time.Second,
+		10*time.Millisecond)
+	require.False(t, srv.Health().IsServing(""))
+
+	// The gRPC server stops as the drain starts, its clients move to other instances
+	require.Eventually(t, func() bool { return srv.Status()[1].State == server.StateStopped }, time.Second,
+		10*time.Millisecond)
+
+	// Still serving during the drain phase, asking clients to close their connection
+	resp, err := http.Get(url + "/ping")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.True(t, resp.Close)
+
+	resp, err = http.Get(url + server.ReadinessPath)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	require.NoError(t, <-shutdownDone)
+	require.GreaterOrEqual(t, time.Since(start), drainDelay)
+	require.NoError(t, <-done)
+}
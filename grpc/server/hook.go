@@ -11,6 +11,14 @@ type ShutdownHook struct {
 	Priority int                         // Lower number = higher priority (executed first)
 	Timeout  time.Duration               // Maximum time allowed for this hook
 	Hook     func(context.Context) error // The actual cleanup function
+
+	// DependsOn lists the names of the hooks that must complete before this one starts, e.g. flushing a Kafka
+	// producer before closing the DB pool. Dependencies must have the same or a lower Priority.
+	DependsOn []string
+
+	// StartupHook is the name of the startup hook whose work this hook undoes, e.g. closing a connection pool
+	// opened at startup. The hook is skipped when that startup hook did not complete.
+	StartupHook string
 }
 
 // ShutdownHooks is a sortable slice of shutdown hooks
@@ -19,3 +27,35 @@ type ShutdownHooks []ShutdownHook
 func (h ShutdownHooks) Len() int           { return len(h) }
 func (h ShutdownHooks) Less(i, j int) bool { return h[i].Priority < h[j].Priority }
 func (h ShutdownHooks) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+// StartupHook represents a function to be executed before any listener opens, e.g. database migrations,
+// cache warmup or waiting for dependencies
+type StartupHook struct {
+	Name     string                      // Human-readable name for logging
+	Priority int                         // Lower number = higher priority (executed first)
+	Timeout  time.Duration               // Maximum time allowed for this hook
+	Hook     func(context.Context) error // The actual startup function
+}
+
+// StartupHooks is a sortable slice of startup hooks
+type StartupHooks []StartupHook
+
+func (h StartupHooks) Len() int           { return len(h) }
+func (h StartupHooks) Less(i, j int) bool { return h[i].Priority < h[j].Priority }
+func (h StartupHooks) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+// ReloadHook represents a function re-applying configuration while the servers keep running, e.g. re-reading
+// the config file, rotating TLS certificates or refreshing API keys
+type ReloadHook struct {
+	Name     string                      // Human-readable name for logging
+	Priority int                         // Lower number = higher priority (executed first)
+	Timeout  time.Duration               // Maximum time allowed for this hook
+	Hook     func(context.Context) error // The actual reload function
+}
+
+// ReloadHooks is a sortable slice of reload hooks
+type ReloadHooks []ReloadHook
+
+func (h ReloadHooks) Len() int           { return len(h) }
+func (h ReloadHooks) Less(i, j int) bool { return h[i].Priority < h[j].Priority }
+func (h ReloadHooks) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
@@ -0,0 +1,81 @@
+package server_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/rainbow-me/platform-tools/grpc/gateway"
+	"github.com/rainbow-me/platform-tools/grpc/server"
+)
+
+// registerHealthCheck exposes the gRPC health check as GET /v1/health, like a generated gateway handler.
+func registerHealthCheck(ctx context.Context, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption) error {
+	conn, err := grpc.NewClient(endpoint, opts...)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+	client := grpc_health_v1.NewHealthClient(conn)
+	return mux.HandlePath(http.MethodGet, "/health", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		res, checkErr := client.Check(r.Context(), &grpc_health_v1.HealthCheckRequest{})
+		if checkErr != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(res.GetStatus().String()))
+	})
+}
+
+func TestServer_InProcessGateway(t *testing.T) {
+	var intercepted atomic.Int32
+	interceptor := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		intercepted.Add(1)
+		return handler(ctx, req)
+	}
+
+	srv, err := server.NewServer(
+		server.WithGRPCServer("grpc", ":0", nil, func(_ *grpc.Server) {}, grpc.UnaryInterceptor(interceptor)),
+		server.WithInProcessGateway("gateway", ":0", "grpc", nil,
+			gateway.WithEndpointRegistration("/v1/", registerHealthCheck),
+		),
+		server.WithAutomaticStop(false),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+	<-srv.Ready()
+
+	resp, err := http.Get("http://" + srv.Addr("gateway").String() + "/v1/health")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, int32(1), intercepted.Load())
+
+	require.NoError(t, srv.GracefulShutdown(context.Background()))
+	require.NoError(t, <-done)
+}
+
+func TestServer_InProcessGateway_UnknownGRPCServer(t *testing.T) {
+	_, err := server.NewServer(
+		server.WithInProcessGateway("gateway", ":0", "missing", nil,
+			gateway.WithEndpointRegistration("/v1/", registerHealthCheck),
+		),
+	)
+	require.ErrorContains(t, err, "unknown gRPC server")
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// connStats counts the connections of a server, across restarts.
+type connStats struct {
+	active   atomic.Int64 // Open connections accepted within the limit
+	rejected atomic.Int64 // Connections refused over the limit
+}
+
+// limitListener tracks the connections of a server and refuses those over max, unless max is zero.
+// With closeRejected, the refused connections are closed right away, which gRPC clients report as Unavailable;
+// otherwise they are returned marked as rejected, for rejectOverloaded to answer 503 to their requests.
+type limitListener struct {
+	net.Listener
+	max           int64
+	stats         *connStats
+	closeRejected bool
+}
+
+func (l *limitListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if active := l.stats.active.Add(1); l.max <= 0 || active <= l.max {
+			return &trackedConn{Conn: conn, stats: l.stats}, nil
+		}
+
+		l.stats.active.Add(-1)
+		l.stats.rejected.Add(1)
+		if !l.closeRejected {
+			return &rejectedConn{Conn: conn}, nil
+		}
+		_ = conn.Close()
+	}
+}
+
+// trackedConn releases its slot in the limit when closed.
+type trackedConn struct {
+	net.Conn
+	stats *connStats
+	once  sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(func() { c.stats.active.Add(-1) })
+	return c.Conn.Close()
+}
+
+// rejectedConn is a connection accepted over the limit of an HTTP server.
+type rejectedConn struct {
+	net.Conn
+}
+
+type rejectedConnKey struct{}
+
+// markRejectedConn is the http.Server.ConnContext flagging the requests of rejected connections.
+func markRejectedConn(ctx context.Context, conn net.Conn) context.Context {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+	if _, ok := conn.(*rejectedConn); ok {
+		return context.WithValue(ctx, rejectedConnKey{}, true)
+	}
+	return ctx
+}
+
+// rejectOverloaded answers 503 to the requests of connections accepted over the limit, and closes them after
+// the response whatever the protocol: "Connection: close" makes the HTTP/2 servers send GOAWAY and close the
+// connection once its streams are done, as HTTP/1 servers do.
+func rejectOverloaded(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rejected, _ := r.Context().Value(rejectedConnKey{}).(bool); rejected {
+			w.Header().Set("Connection", "close")
+			http.Error(w, "too many connections", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitBody answers 413 to requests declaring a body larger than maxBytes, and stops the handlers reading more
+// than maxBytes of the others with an *http.MaxBytesError.
+func limitBody(next http.Handler, maxBytes int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxBytes {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		next.ServeHTTP(w, r)
+	})
+}
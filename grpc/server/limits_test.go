@@ -0,0 +1,236 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/rainbow-me/platform-tools/grpc/gateway"
+	"github.com/rainbow-me/platform-tools/grpc/server"
+)
+
+func TestServer_HTTPLimits(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	})
+
+	srv, err := server.NewServer(
+		server.WithHTTPServer("http", ":0", mux, server.WithHTTPMaxHeaderBytes(1024), server.WithHTTPMaxBodyBytes(10)),
+		server.WithHTTPServer("limited", ":0", mux, server.WithHTTPHealthProbes(), server.WithHTTPMaxConnections(1)),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(5*time.Second), // net/http lingers 500ms before closing a partly read request,
+	)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+	require.NoError(t, srv.WaitReady(context.Background()))
+	url := "http://" + srv.Addr("http").String()
+
+	post := func(body io.Reader) int {
+		resp, err := http.Post(url+"/upload", "text/plain", body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+	require.Equal(t, http.StatusOK, post(strings.NewReader("small")))
+	require.Equal(t, http.StatusRequestEntityTooLarge, post(strings.NewReader("larger than ten bytes")))
+	// Without a declared length, the handler gets an *http.MaxBytesError and picks the status
+	require.Equal(t, http.StatusBadRequest, post(io.MultiReader(strings.NewReader("chunked body"))))
+
+	req, err := http.NewRequest(http.MethodGet, url+"/upload", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Large", strings.Repeat("a", 8<<10))
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusRequestHeaderFieldsTooLarge, resp.StatusCode)
+
+	// The first client keeps its connection open, a second connection is over the limit except for the probes
+	url = "http://" + srv.Addr("limited").String()
+	client := &http.Client{Transport: &http.Transport{}}
+	defer client.CloseIdleConnections()
+	resp, err = client.Get(url + "/upload")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(url + "/upload")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	resp, err = http.Get(url + server.LivenessPath)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	statuses := srv.Status()
+	require.Equal(t, int64(1), statuses[1].Connections)
+	require.Equal(t, int64(2), statuses[1].Rejected)
+	require.Zero(t, statuses[0].Rejected)
+
+	require.NoError(t, srv.GracefulShutdown(context.Background()))
+	require.NoError(t, <-done)
+}
+
+func TestServer_GatewayBodyLimit(t *testing.T) {
+	// Decodes the body like a generated gateway handler
+	registerEcho := func(_ context.Context, mux *runtime.ServeMux, _ string, _ []grpc.DialOption) error {
+		return mux.HandlePath(http.MethodPost, "/echo", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			var req grpc_health_v1.HealthCheckRequest
+			_, marshaler := runtime.MarshalerForRequest(mux, r)
+			if err := marshaler.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+				runtime.HTTPError(r.Context(), mux, marshaler, w, r, status.Errorf(codes.InvalidArgument, "%v", err))
+			}
+		})
+	}
+	srv, err := server.NewServer(
+		server.WithGRPCServer("grpc", ":0", nil, func(_ *grpc.Server) {}),
+		server.WithInProcessGateway("gateway", ":0", "grpc", []server.HTTPConfigOption{server.WithHTTPMaxBodyBytes(10)},
+			gateway.WithEndpointRegistration("/v1/", registerEcho),
+		),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(5*time.Second), // net/http lingers 500ms before closing a partly read request,
+	)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+	require.NoError(t, srv.WaitReady(context.Background()))
+
+	url := "http://" + srv.Addr("gateway").String() + "/v1/echo"
+	post := func(body io.Reader) int {
+		resp, err := http.Post(url, "application/json", body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+	require.Equal(t, http.StatusOK, post(strings.NewReader("{}")))
+	require.Equal(t, http.StatusBadRequest, post(io.MultiReader(strings.NewReader("{"))))
+	require.Equal(t, http.StatusRequestEntityTooLarge,
+		post(io.MultiReader(strings.NewReader(`{"service":"larger than ten bytes"}`))))
+
+	require.NoError(t, srv.GracefulShutdown(context.Background()))
+	require.NoError(t, <-done)
+}
+
+func TestServer_HTTP2ConnectionLimit(t *testing.T) {
+	srv, err := server.NewServer(
+		server.WithHTTPServer("h2c", ":0", http.NewServeMux(), server.WithHTTPMaxConnections(1),
+			server.WithMultiplexedGRPC(nil, func(_ *grpc.Server) {})),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(5*time.Second), // HTTP/2 waits up to 1s for clients to close after GOAWAY
+	)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+	require.NoError(t, srv.WaitReady(context.Background()))
+
+	// h2c connections, the first one within the limit
+	connect := func() *http2.ClientConn {
+		conn, err := net.Dial("tcp", srv.Addr("h2c").String())
+		require.NoError(t, err)
+		cc, err := (&http2.Transport{AllowHTTP: true}).NewClientConn(conn)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = cc.Close() })
+		return cc
+	}
+	get := func(cc *http2.ClientConn) int {
+		req, err := http.NewRequest(http.MethodGet, "http://"+srv.Addr("h2c").String()+"/", nil)
+		require.NoError(t, err)
+		resp, err := cc.RoundTrip(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+	accepted := connect()
+	require.Equal(t, http.StatusNotFound, get(accepted))
+
+	// The rejected connection is closed by the server after its first response
+	rejected := connect()
+	require.Equal(t, http.StatusServiceUnavailable, get(rejected))
+	require.Eventually(t, func() bool { return rejected.State().Closed }, time.Second, 5*time.Millisecond)
+	require.False(t, accepted.State().Closed)
+	require.Equal(t, int64(1), srv.Status()[0].Rejected)
+
+	require.NoError(t, srv.GracefulShutdown(context.Background()))
+	require.NoError(t, <-done)
+}
+
+func TestServer_GRPCLimits(t *testing.T) {
+	srv, err := server.NewServer(
+		server.WithGRPCConfig(server.GRPCConfig{
+			Name:                 "grpc",
+			Address:              ":0",
+			SetupFunc:            func(_ *grpc.Server) {},
+			MaxConnections:       1,
+			MaxConcurrentStreams: 10,
+		}),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+	require.NoError(t, srv.WaitReady(context.Background()))
+
+	check := func() error {
+		conn, err := grpc.NewClient(srv.Addr("grpc").String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		return err
+	}
+	require.NoError(t, check())
+
+	// The second connection is closed: Unavailable rather than queued
+	err = check()
+	require.Equal(t, codes.Unavailable, status.Code(err), err)
+	require.Equal(t, int64(1), srv.Status()[0].Connections)
+	require.Positive(t, srv.Status()[0].Rejected)
+
+	require.NoError(t, srv.Stop())
+	require.NoError(t, <-done)
+}
+
+func TestServer_LimitsValidation(t *testing.T) {
+	_, err := server.NewServer(server.WithGRPCConfig(server.GRPCConfig{
+		Name:                 "grpc",
+		Address:              ":0",
+		GRPCServer:           grpc.NewServer(),
+		MaxConcurrentStreams: 10,
+	}))
+	require.ErrorContains(t, err, "pass grpc.MaxConcurrentStreams when creating it instead")
+
+	_, err = server.NewServer(server.WithHTTPServer("http", ":0", http.NewServeMux(), server.WithHTTPMaxBodyBytes(-1)))
+	require.ErrorContains(t, err, "negative connection, header or body limit")
+}
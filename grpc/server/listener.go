@@ -0,0 +1,105 @@
+package server
+
+import (
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// staleSocketDialTimeout bounds the dial checking whether an existing unix socket is still in use.
+const staleSocketDialTimeout = time.Second
+
+// Addr returns the address the named server is bound to, e.g. the port picked for ":0", or nil if the server
+// is not listening yet.
+func (s *Server) Addr(name string) net.Addr {
+	s.serverMu.RLock()
+	defer s.serverMu.RUnlock()
+	return s.addrs[name]
+}
+
+// Ready returns a channel closed once every server is listening, after which Addr reports the bound addresses.
+// The channel is never closed if a server fails to listen or a startup hook fails.
+func (s *Server) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// listen returns the pre-built listener or binds address on network, and records the bound address.
+func (s *Server) listen(name string, lis net.Listener, network, address string) (net.Listener, error) {
+	if lis == nil {
+		var err error
+		lis, err = bind(network, address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.serverMu.Lock()
+	s.addrs[name] = lis.Addr()
+	s.serverMu.Unlock()
+
+	return lis, nil
+}
+
+// bind listens on address, removing the stale socket file left by a previous process for unix sockets.
+func bind(network, address string) (net.Listener, error) {
+	switch network {
+	case "", NetworkTCP:
+		return net.Listen(NetworkTCP, address)
+	case NetworkUnix:
+		if err := removeStaleSocket(address); err != nil {
+			return nil, err
+		}
+		return net.Listen(NetworkUnix, address)
+	default:
+		return nil, errors.Newf("unsupported network %q", network)
+	}
+}
+
+// removeStaleSocket removes the unix socket at path if no process accepts connections on it anymore. A socket
+// still in use and files that are not sockets are left untouched, and reported as errors.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat unix socket %s: %w", path, err)
+	}
+	if info.Mode().Type() != fs.ModeSocket {
+		return errors.Newf("%s already exists and is not a unix socket", path)
+	}
+
+	conn, err := net.DialTimeout(NetworkUnix, path, staleSocketDialTimeout)
+	if err == nil {
+		_ = conn.Close()
+		return errors.Newf("unix socket %s is in use by another process", path)
+	}
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("failed to check unix socket %s: %w", path, err)
+	}
+	if err = os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale unix socket %s: %w", path, err)
+	}
+	return nil
+}
+
+// bindKey returns the key used to detect configs binding the same address. Pre-built listeners and random
+// ports (":0") never conflict.
+func bindKey(lis net.Listener, network, address string) (string, bool) {
+	if lis != nil || address == "" {
+		return "", false
+	}
+	if network == "" {
+		network = NetworkTCP
+	}
+	if network == NetworkTCP && strings.HasSuffix(address, ":0") {
+		return "", false
+	}
+	return network + "://" + address, true
+}
@@ -0,0 +1,132 @@
+package server_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/rainbow-me/platform-tools/grpc/server"
+)
+
+func TestServer_Listeners(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "admin.sock")
+	grpcListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv, err := server.NewServer(
+		server.WithHTTPServer("http", ":0", http.NewServeMux(), server.WithHTTPHealthProbes()),
+		server.WithHTTPServer("sidecar", socket, http.NewServeMux(),
+			server.WithHTTPNetwork(server.NetworkUnix), server.WithHTTPHealthProbes()),
+		server.WithGRPCServer("grpc", ":0", nil, func(_ *grpc.Server) {}),
+		server.WithGRPCConfig(server.GRPCConfig{
+			Name:      "grpc-prebuilt",
+			Listener:  grpcListener,
+			SetupFunc: func(_ *grpc.Server) {},
+		}),
+		server.WithAutomaticStop(false),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+	require.Nil(t, srv.Addr("http"))
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+
+	select {
+	case <-srv.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("server not ready")
+	}
+
+	// HTTP on a random port
+	httpAddr := srv.Addr("http").(*net.TCPAddr)
+	require.NotZero(t, httpAddr.Port)
+	resp, err := http.Get("http://" + httpAddr.String() + server.ReadinessPath)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// HTTP on a unix socket
+	require.Equal(t, socket, srv.Addr("sidecar").String())
+	unixClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	resp, err = unixClient.Get("http://sidecar" + server.LivenessPath)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// gRPC on a random port and on a pre-built listener
+	require.Equal(t, grpcListener.Addr(), srv.Addr("grpc-prebuilt"))
+	for _, name := range []string{"grpc", "grpc-prebuilt"} {
+		conn, dialErr := grpc.NewClient(srv.Addr(name).String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, dialErr)
+		res, checkErr := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		require.NoError(t, checkErr)
+		require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, res.GetStatus())
+		require.NoError(t, conn.Close())
+	}
+
+	require.NoError(t, srv.GracefulShutdown(context.Background()))
+	require.NoError(t, <-done)
+}
+
+func TestServer_UnixSocketReuse(t *testing.T) {
+	serve := func(socket string) error {
+		srv, err := server.NewServer(
+			server.WithHTTPServer("sidecar", socket, http.NewServeMux(), server.WithHTTPNetwork(server.NetworkUnix)),
+			server.WithSignalHandling(false),
+			server.WithShutdownTimeout(time.Second),
+		)
+		require.NoError(t, err)
+		done := make(chan error, 1)
+		go func() {
+			done <- srv.Serve()
+		}()
+		select {
+		case <-srv.Ready():
+			require.NoError(t, srv.GracefulShutdown(context.Background()))
+			return <-done
+		case err = <-done:
+			return err
+		}
+	}
+
+	// A socket left behind by a process that exited is removed
+	stale := filepath.Join(t.TempDir(), "stale.sock")
+	lis, err := net.ListenUnix("unix", &net.UnixAddr{Name: stale, Net: "unix"})
+	require.NoError(t, err)
+	lis.SetUnlinkOnClose(false)
+	require.NoError(t, lis.Close())
+	require.NoError(t, serve(stale))
+
+	// A socket still accepting connections is left to its server
+	live := filepath.Join(t.TempDir(), "live.sock")
+	lis, err = net.ListenUnix("unix", &net.UnixAddr{Name: live, Net: "unix"})
+	require.NoError(t, err)
+	defer func() { _ = lis.Close() }()
+	require.ErrorContains(t, serve(live), "is in use by another process")
+	_, err = os.Stat(live)
+	require.NoError(t, err)
+
+	// Other files are never removed
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("port: 80"), 0o600))
+	require.ErrorContains(t, serve(file), "already exists and is not a unix socket")
+	_, err = os.Stat(file)
+	require.NoError(t, err)
+}
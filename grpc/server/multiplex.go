@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+)
+
+// multiplexPollInterval is how often shutdown checks for in-flight requests on a multiplexed server
+const multiplexPollInterval = 10 * time.Millisecond
+
+// multiplexedServer is a gRPC server sharing the listener of an HTTP server.
+type multiplexedServer struct {
+	grpc     *grpc.Server
+	inFlight atomic.Int64 // Requests being served, gRPC and HTTP, including those on hijacked h2c connections
+}
+
+// WithMultiplexedGRPC serves a gRPC server on the listener of the HTTP server: HTTP/2 requests with an
+// application/grpc content-type go to the gRPC server, everything else to the HTTP handler. Cleartext
+// listeners accept HTTP/2 without TLS (h2c); TLS listeners negotiate HTTP/2 with ALPN.
+// grpcServer and setupFunc behave as in WithGRPCServer. Transport options such as grpc.Creds do not apply,
+// use WithHTTPTLS instead.
+func WithMultiplexedGRPC(grpcServer *grpc.Server, setupFunc func(*grpc.Server), grpcOpts ...grpc.ServerOption) HTTPConfigOption {
+	return func(c *HTTPConfig) {
+		c.GRPC = &GRPCConfig{
+			GRPCServer: grpcServer,
+			SetupFunc:  setupFunc,
+			GRPCOpts:   grpcOpts,
+		}
+	}
+}
+
+// multiplex creates the gRPC server of config and returns it with the handler splitting traffic between it and
+// the HTTP handler. It configures server for HTTP/2.
+func (s *Server) multiplex(
+	config HTTPConfig,
+	server *http.Server,
+	handler http.Handler,
+) (*multiplexedServer, http.Handler, error) {
+	grpcConfig := *config.GRPC
+	grpcConfig.Name = config.Name
+	mux := &multiplexedServer{grpc: s.newGRPCServer(grpcConfig)}
+	grpcHandler := rejectOverloaded(mux.grpc) // gRPC clients report 503 as Unavailable
+
+	split := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.inFlight.Add(1)
+		defer mux.inFlight.Add(-1)
+
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcHandler.ServeHTTP(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+
+	if config.TLS != nil {
+		// ServeTLS negotiates HTTP/2 with ALPN and sends GOAWAY on Shutdown
+		return mux, split, nil
+	}
+
+	// Sharing h2s with server makes Shutdown send GOAWAY on the h2c connections too
+	h2s := &http2.Server{IdleTimeout: config.IdleTimeout}
+	if err := http2.ConfigureServer(server, h2s); err != nil {
+		mux.grpc.Stop()
+		return nil, nil, err
+	}
+	return mux, h2c.NewHandler(split, h2s), nil
+}
+
+// stopMultiplexed stops the gRPC server sharing the listener of the named HTTP server, once the HTTP server
+// is shut down. When graceful, it first waits for the in-flight requests, which Shutdown does not track on
+// h2c connections; gRPC's GracefulStop cannot be used as it does not support ServeHTTP.
+func (s *Server) stopMultiplexed(ctx context.Context, name string, isGraceful bool) error {
+	s.serverMu.RLock()
+	mux, ok := s.multiplexed[name]
+	s.serverMu.RUnlock()
+	if !ok {
+		return nil
+	}
+	defer mux.grpc.Stop()
+
+	if !isGraceful {
+		return nil
+	}
+
+	ticker := time.NewTicker(multiplexPollInterval)
+	defer ticker.Stop()
+	for mux.inFlight.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
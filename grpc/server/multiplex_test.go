@@ -0,0 +1,160 @@
+package server_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/rainbow-me/platform-tools/common/test"
+	"github.com/rainbow-me/platform-tools/grpc/server"
+	"github.com/rainbow-me/platform-tools/grpc/tlsconfig"
+)
+
+func TestServer_MultiplexedGRPC(t *testing.T) {
+	dir := t.TempDir()
+	ca := test.NewCA(t)
+	caFile := ca.WriteBundle(t, dir)
+	certFile, keyFile := ca.Issue(t, dir, "server", 2)
+	serverCerts, err := tlsconfig.Load(tlsconfig.Config{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	clientCerts, err := tlsconfig.Load(tlsconfig.Config{CAFile: caFile})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		opts       []server.HTTPConfigOption
+		scheme     string
+		httpClient *http.Client
+		grpcCreds  credentials.TransportCredentials
+	}{
+		{
+			name:       "h2c",
+			scheme:     "http",
+			httpClient: http.DefaultClient,
+			grpcCreds:  insecure.NewCredentials(),
+		},
+		{
+			name:       "tls",
+			opts:       []server.HTTPConfigOption{server.WithHTTPTLS(serverCerts)},
+			scheme:     "https",
+			httpClient: &http.Client{Transport: &http.Transport{TLSClientConfig: clientCerts.ClientTLS()}},
+			grpcCreds:  credentials.NewTLS(clientCerts.ClientTLS()),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/hello", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("hello"))
+			})
+
+			opts := append([]server.HTTPConfigOption{
+				server.WithMultiplexedGRPC(nil, func(_ *grpc.Server) {}),
+				server.WithHTTPHealthProbes(),
+			}, tt.opts...)
+			srv, err := server.NewServer(
+				server.WithHTTPServer("api", ":0", mux, opts...),
+				server.WithAutomaticStop(false),
+				server.WithSignalHandling(false),
+				server.WithShutdownTimeout(5*time.Second), // HTTP/2 waits up to 1s for clients to close after GOAWAY
+			)
+			require.NoError(t, err)
+
+			done := make(chan error)
+			go func() {
+				done <- srv.Serve()
+			}()
+			<-srv.Ready()
+			addr := localhost(srv.Addr("api"))
+
+			// HTTP handler and probes
+			resp, err := tt.httpClient.Get(tt.scheme + "://" + addr + "/hello")
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			resp, err = tt.httpClient.Get(tt.scheme + "://" + addr + server.ReadinessPath)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			// gRPC on the same port
+			conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(tt.grpcCreds))
+			require.NoError(t, err)
+			res, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+			require.NoError(t, err)
+			require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, res.GetStatus())
+
+			require.NoError(t, srv.GracefulShutdown(context.Background()))
+			require.NoError(t, <-done)
+			require.NoError(t, conn.Close())
+		})
+	}
+}
+
+func TestServer_MultiplexedGRPC_InFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		<-release
+		_, _ = w.Write([]byte("done"))
+	})
+
+	srv, err := server.NewServer(
+		server.WithHTTPServer("api", ":0", mux, server.WithMultiplexedGRPC(nil, func(_ *grpc.Server) {})),
+		server.WithAutomaticStop(false),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(5*time.Second),
+	)
+	require.NoError(t, err)
+
+	go func() {
+		_ = srv.Serve()
+	}()
+	<-srv.Ready()
+
+	// HTTP/2 with prior knowledge, as gRPC clients do
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+	result := make(chan error)
+	go func() {
+		resp, getErr := client.Get("http://" + localhost(srv.Addr("api")) + "/slow")
+		if getErr == nil {
+			getErr = resp.Body.Close()
+		}
+		result <- getErr
+	}()
+	<-started
+
+	shutdown := make(chan error)
+	go func() {
+		shutdown <- srv.GracefulShutdown(context.Background())
+	}()
+
+	select {
+	case <-shutdown:
+		t.Fatal("shutdown did not wait for the in-flight request")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-result)
+	require.NoError(t, <-shutdown)
+}
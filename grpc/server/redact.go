@@ -0,0 +1,199 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// redactedValue replaces the values of sensitive configuration fields
+const redactedValue = "***"
+
+// sensitiveNames are the substrings of field and key names whose values are redacted, compared in lower case
+// without separators
+var sensitiveNames = []string{"password", "passwd", "secret", "token", "credential", "dsn", "url", "authorization",
+	"apikey", "accesskey", "privatekey"}
+
+// cycleValue replaces the values referencing a pointer or map being redacted
+const cycleValue = "<cycle>"
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	urlType      = reflect.TypeOf(url.URL{})
+)
+
+// redactConfig converts config to maps, slices and values that can be encoded to JSON, replacing sensitive values.
+// A field is sensitive when tagged redact:"true" or when its name contains one of sensitiveNames. The keys of maps
+// held by fields named like keys (e.g. Keys, api_keys) are redacted, and so are the other values of such fields.
+// Credentials are stripped from URL values. Field names come from the mapstructure, yaml or json tags, in that
+// order, then from the Go name.
+func redactConfig(config any) any {
+	if config == nil {
+		return nil
+	}
+	r := redactor{visiting: make(map[uintptr]bool)}
+	return r.value(reflect.ValueOf(config))
+}
+
+// redactor tracks the pointers and maps on the path being redacted, to stop at cycles.
+type redactor struct {
+	visiting map[uintptr]bool
+}
+
+// named redacts v, held by a field or map key named name.
+func (r *redactor) named(name string, v reflect.Value, tagged bool) any {
+	switch {
+	case tagged || isSensitiveName(name):
+		return redactedValue
+	case isKeyHolderName(name):
+		return r.keys(v)
+	default:
+		return r.value(v)
+	}
+}
+
+// keys redacts the keys of a map, or the whole of any other value.
+func (r *redactor) keys(v reflect.Value) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Map {
+		return redactedValue
+	}
+
+	values := make([]reflect.Value, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		values = append(values, iter.Value())
+	}
+	result := make(map[string]any, len(values))
+	for i, value := range values {
+		result[fmt.Sprintf("%s%d", redactedValue, i+1)] = r.value(value)
+	}
+	return result
+}
+
+func (r *redactor) value(v reflect.Value) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Pointer {
+			if r.visiting[v.Pointer()] {
+				return cycleValue
+			}
+			r.visiting[v.Pointer()] = true
+			defer delete(r.visiting, v.Pointer())
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	if v.Type() == urlType {
+		u := v.Interface().(url.URL) //nolint:forcetypeassert // Checked above
+		return redactURL(&u)
+	}
+	if stringer, ok := v.Interface().(fmt.Stringer); ok && v.Kind() == reflect.Struct {
+		return stringer.String() // e.g. time.Time
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		result := make(map[string]any, v.NumField())
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := configFieldName(field)
+			if name == "-" {
+				continue
+			}
+			result[name] = r.named(name, v.Field(i), field.Tag.Get("redact") == "true")
+		}
+		return result
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		if r.visiting[v.Pointer()] {
+			return cycleValue
+		}
+		r.visiting[v.Pointer()] = true
+		defer delete(r.visiting, v.Pointer())
+
+		result := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			result[key] = r.named(key, iter.Value(), false)
+		}
+		return result
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		result := make([]any, v.Len())
+		for i := range v.Len() {
+			result[i] = r.value(v.Index(i))
+		}
+		return result
+	case reflect.String:
+		return redactURLString(v.String())
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil
+	default:
+		return v.Interface()
+	}
+}
+
+// redactURLString strips the credentials of s if it is a URL, e.g. a DSN under a name that is not sensitive.
+func redactURLString(s string) string {
+	if !strings.Contains(s, "@") {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	return redactURL(u)
+}
+
+// redactURL returns u without its user info.
+func redactURL(u *url.URL) string {
+	stripped := *u
+	stripped.User = nil
+	return stripped.String()
+}
+
+func configFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"mapstructure", "yaml", "json"} {
+		if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func isSensitiveName(name string) bool {
+	name = strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(name))
+	for _, sensitive := range sensitiveNames {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// isKeyHolderName reports whether name designates keys, e.g. Keys or signing_key, whose map keys are redacted.
+func isKeyHolderName(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasSuffix(name, "key") || strings.HasSuffix(name, "keys")
+}
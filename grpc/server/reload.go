@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/rainbow-me/platform-tools/common/logger"
+)
+
+// ReloadReport is the outcome of the reload hooks, in execution order
+type ReloadReport struct {
+	Hooks    []HookResult
+	Duration time.Duration // Total time spent running the hooks
+}
+
+// Err returns the errors of the failed hooks.
+func (r *ReloadReport) Err() error {
+	var errs []error
+	for _, hook := range r.Hooks {
+		if hook.Err != nil {
+			errs = append(errs, fmt.Errorf("reload hook %s: %w", hook.Name, hook.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithReloadHook adds a hook run on every reload, see Reload.
+func WithReloadHook(hook ReloadHook) Option {
+	return func(s *Server) error {
+		if hook.Hook == nil {
+			return fmt.Errorf("reload hook %s has no function", hook.Name)
+		}
+		if hook.Timeout == 0 {
+			hook.Timeout = DefaultReloadHookTimeout
+		}
+		s.reloadHooks = append(s.reloadHooks, hook)
+		return nil
+	}
+}
+
+// WithReloadSignals reloads the Server instead of shutting it down when one of the signals is received,
+// typically syscall.SIGHUP. The signals are handled even when WithSignalHandling is disabled.
+func WithReloadSignals(signals ...os.Signal) Option {
+	return func(s *Server) error {
+		s.reloadSignals = append(s.reloadSignals, signals...)
+		return nil
+	}
+}
+
+// Reload runs the reload hooks while the servers keep serving. Hooks with the same priority run concurrently and
+// priorities run in order; a failed hook does not prevent the next ones from running, so that e.g. certificates
+// are still rotated when the config file is invalid. Concurrent calls run one after the other.
+func (s *Server) Reload(ctx context.Context) *ReloadReport {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	report := &ReloadReport{}
+	if len(s.reloadHooks) == 0 {
+		s.logger.Info("No reload hooks registered")
+		s.setReloadReport(report)
+		return report
+	}
+
+	s.logger.Info("Executing reload hooks", logger.Int("count", len(s.reloadHooks)))
+
+	hooks := slices.Clone(s.reloadHooks)
+	sort.Stable(hooks)
+	report.Hooks = make([]HookResult, len(hooks))
+	start := time.Now()
+
+	for groupStart := 0; groupStart < len(hooks); {
+		groupEnd := groupStart
+		for groupEnd < len(hooks) && hooks[groupEnd].Priority == hooks[groupStart].Priority {
+			groupEnd++
+		}
+
+		var wg sync.WaitGroup
+		for i := groupStart; i < groupEnd; i++ {
+			wg.Add(1)
+			go func(i int, h ReloadHook) {
+				defer wg.Done()
+				hookStart := time.Now()
+				err := runHook(ctx, h.Timeout, h.Hook)
+				report.Hooks[i] = HookResult{Name: h.Name, Priority: h.Priority, Duration: time.Since(hookStart), Err: err}
+			}(i, hooks[i])
+		}
+		wg.Wait()
+		groupStart = groupEnd
+	}
+
+	report.Duration = time.Since(start)
+	s.logReloadReport(report)
+	s.setReloadReport(report)
+	return report
+}
+
+// LastReloadReport returns the result of the last reload, nil if the Server has not reloaded.
+func (s *Server) LastReloadReport() *ReloadReport {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	return s.reloadReport
+}
+
+func (s *Server) setReloadReport(report *ReloadReport) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.reloadReport = report
+}
+
+func (s *Server) logReloadReport(report *ReloadReport) {
+	fields := []logger.Field{
+		logger.Duration("duration", report.Duration),
+		logger.Array("hooks", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for i := range report.Hooks {
+				if err := arr.AppendObject(&report.Hooks[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})),
+	}
+	if err := report.Err(); err != nil {
+		s.logger.Error("Some reload hooks failed", append(fields, logger.Error(err))...)
+		return
+	}
+	s.logger.Info("All reload hooks completed", fields...)
+}
+
+// handleReloadSignals reloads the Server every time one of the reload signals is received, until shutdown.
+func (s *Server) handleReloadSignals() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, s.reloadSignals...)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-s.shutdownCtx.Done():
+				return
+			case sig := <-ch:
+				s.logger.Info("Received reload signal", logger.String("signal", sig.String()))
+				s.Reload(s.shutdownCtx)
+			}
+		}
+	}()
+}
@@ -0,0 +1,93 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rainbow-me/platform-tools/grpc/server"
+)
+
+func TestServer_Reload(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	hook := func(name string, err error) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return err
+		}
+	}
+
+	srv, err := server.NewServer(
+		server.WithReloadHook(server.ReloadHook{Name: "api-keys", Priority: 2, Hook: hook("api-keys", nil)}),
+		server.WithReloadHook(server.ReloadHook{Name: "config", Priority: 1, Hook: hook("config", errors.New("invalid yaml"))}),
+		server.WithReloadHook(server.ReloadHook{Name: "hang", Priority: 3, Timeout: 10 * time.Millisecond,
+			Hook: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}}),
+	)
+	require.NoError(t, err)
+	require.Nil(t, srv.LastReloadReport())
+
+	report := srv.Reload(context.Background())
+	require.Equal(t, []string{"config", "api-keys"}, order)
+	require.Len(t, report.Hooks, 3)
+	require.Equal(t, "config", report.Hooks[0].Name)
+	require.ErrorContains(t, report.Hooks[0].Err, "invalid yaml")
+	require.NoError(t, report.Hooks[1].Err)
+	require.ErrorIs(t, report.Hooks[2].Err, server.ErrHookTimeout)
+	require.ErrorContains(t, report.Err(), "reload hook config: invalid yaml")
+	require.Same(t, report, srv.LastReloadReport())
+}
+
+func TestServer_ReloadSignal(t *testing.T) {
+	reloaded := make(chan struct{}, 1)
+	srv, err := server.NewServer(
+		server.WithHTTPServer("http", ":0", http.NewServeMux(), server.WithHTTPHealthProbes()),
+		server.WithReloadSignals(syscall.SIGHUP),
+		server.WithReloadHook(server.ReloadHook{Name: "certs", Hook: func(context.Context) error {
+			reloaded <- struct{}{}
+			return nil
+		}}),
+		server.WithSignalHandling(true),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+	require.NoError(t, srv.WaitReady(context.Background()))
+
+	// SIGHUP reloads instead of shutting down
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatal("reload hook not called")
+	}
+	select {
+	case err = <-done:
+		t.Fatalf("server stopped on SIGHUP: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	resp, err := http.Get("http://" + srv.Addr("http").String() + server.ReadinessPath)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, srv.GracefulShutdown(context.Background()))
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rainbow-me/platform-tools/common/logger"
+)
+
+var (
+	DefaultRestartInitialBackoff = time.Second
+	DefaultRestartMaxBackoff     = 30 * time.Second
+	DefaultRestartStablePeriod   = time.Minute
+)
+
+// maxRestartEvents is the number of restart events kept by the Server
+const maxRestartEvents = 100
+
+// RestartMode tells whether a sub-server is restarted after it fails
+type RestartMode int
+
+const (
+	RestartNever     RestartMode = iota // A failure is reported to the Server, the default
+	RestartOnFailure                    // A failure restarts the sub-server after a backoff
+)
+
+// RestartPolicy configures how a failed HTTP or gRPC sub-server is restarted. A failure is a listen error or
+// Serve returning an error other than the server being closed.
+type RestartPolicy struct {
+	Mode           RestartMode
+	MaxRestarts    int           // Restarts before giving up, 0 for unlimited
+	InitialBackoff time.Duration // Wait before the first restart, doubled after each restart
+	MaxBackoff     time.Duration // Upper bound of the wait between restarts
+	// StablePeriod is how long a sub-server must stay up for a failure not to count as consecutive to the previous
+	// ones: the restart count towards MaxRestarts and the backoff start over. Defaults to
+	// DefaultRestartStablePeriod.
+	StablePeriod time.Duration
+	// Optional sub-servers, e.g. a debug listener, never stop the Server: giving up on them is logged and
+	// recorded, and readiness does not wait for them to listen.
+	Optional bool
+}
+
+// RestartOnFailureUpTo returns a policy restarting a failed sub-server up to maxRestarts times, 0 for unlimited,
+// with the default exponential backoff
+func RestartOnFailureUpTo(maxRestarts int) RestartPolicy {
+	return RestartPolicy{Mode: RestartOnFailure, MaxRestarts: maxRestarts}
+}
+
+// backoff returns the wait before the given restart, counted from 0.
+func (p RestartPolicy) backoff(restart int) time.Duration {
+	backoff, maxBackoff := p.InitialBackoff, p.MaxBackoff
+	if backoff <= 0 {
+		backoff = DefaultRestartInitialBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultRestartMaxBackoff
+	}
+	for range restart {
+		backoff *= 2
+		if backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return min(backoff, maxBackoff)
+}
+
+// stable reports whether a sub-server up since upSince ran long enough for its failure to reset the restart count.
+func (p RestartPolicy) stable(upSince time.Time) bool {
+	period := p.StablePeriod
+	if period <= 0 {
+		period = DefaultRestartStablePeriod
+	}
+	return time.Since(upSince) >= period
+}
+
+// RestartEvent records a failure of a sub-server and what the Server did about it
+type RestartEvent struct {
+	Server  string        // Name of the sub-server
+	Time    time.Time     // When the failure happened
+	Err     error         // The failure
+	Restart int           // Number of the restart scheduled, counted from 1; 0 when giving up
+	Backoff time.Duration // Wait before the restart
+	GaveUp  bool          // The policy does not allow another restart
+}
+
+// RestartEvents returns the latest failures of sub-servers, oldest first.
+func (s *Server) RestartEvents() []RestartEvent {
+	s.restartMu.Lock()
+	defer s.restartMu.Unlock()
+	events := make([]RestartEvent, len(s.restartEvents))
+	copy(events, s.restartEvents)
+	return events
+}
+
+func (s *Server) recordRestartEvent(event RestartEvent) {
+	s.restartMu.Lock()
+	defer s.restartMu.Unlock()
+	s.restartEvents = append(s.restartEvents, event)
+	if len(s.restartEvents) > maxRestartEvents {
+		s.restartEvents = s.restartEvents[len(s.restartEvents)-maxRestartEvents:]
+	}
+}
+
+// supervise runs a sub-server in a goroutine and restarts it on failure according to policy. run serves a new
+// instance of the sub-server until it stops, calls listening once it listens, and returns nil when it was closed
+// by the Server. The first time a sub-server listens, or gives up, counts towards the readiness of the Server.
+func (s *Server) supervise(kind, name string, policy RestartPolicy, run func(listening func()) error) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.runSupervised(kind, name, policy, run)
+	}()
+}
+
+// runSupervised runs and restarts a sub-server until it stops for good, see supervise.
+func (s *Server) runSupervised(kind, name string, policy RestartPolicy, run func(listening func()) error) {
+	// Readiness counts the first outcome only: listening, giving up or shutting down before listening
+	var once sync.Once
+	ready := func(listened bool) {
+		once.Do(func() {
+			if !listened {
+				s.listenFailed.Store(true)
+			}
+			s.listenWG.Done()
+		})
+	}
+	if policy.Optional {
+		ready(true)
+	}
+	var upSince atomic.Pointer[time.Time] // When the current instance started listening or running
+	listening := func() {
+		now := time.Now()
+		upSince.Store(&now)
+		if kind == KindWorker {
+			s.setState(name, StateRunning, nil)
+		} else {
+			s.setState(name, StateListening, nil)
+		}
+		ready(true)
+	}
+
+	for restart := 0; ; restart++ {
+		s.setState(name, StateStarting, nil)
+		upSince.Store(nil)
+		err := run(listening)
+		if err == nil || s.shutdownCtx.Err() != nil {
+			s.setState(name, StateStopped, nil)
+			ready(false)
+			return
+		}
+		s.setState(name, StateFailed, err)
+		if since := upSince.Load(); restart > 0 && since != nil && policy.stable(*since) {
+			restart = 0 // Not consecutive to the previous failures
+		}
+
+		event := RestartEvent{Server: name, Time: time.Now(), Err: err}
+		if policy.Mode != RestartOnFailure || (policy.MaxRestarts > 0 && restart >= policy.MaxRestarts) {
+			event.GaveUp = true
+			s.recordRestartEvent(event)
+			ready(false)
+			if policy.Mode == RestartOnFailure {
+				s.logger.Error("Giving up restarting server", logger.String("name", name),
+					logger.Int("restarts", restart), logger.Error(err))
+			}
+			if policy.Optional {
+				s.logger.Error("Optional server stopped, the other servers keep running",
+					logger.String("name", name), logger.Error(err))
+				return
+			}
+			s.errChan <- err
+			return
+		}
+
+		event.Restart = restart + 1
+		event.Backoff = policy.backoff(restart)
+		s.recordRestartEvent(event)
+		s.countRestart(name)
+		s.logger.Warn("Restarting failed server", logger.String("kind", kind), logger.String("name", name),
+			logger.Int("restart", event.Restart), logger.Duration("backoff", event.Backoff), logger.Error(err))
+
+		timer := time.NewTimer(event.Backoff)
+		select {
+		case <-timer.C:
+		case <-s.shutdownCtx.Done():
+			timer.Stop()
+			s.setState(name, StateStopped, nil)
+			ready(false)
+			return
+		}
+	}
+}
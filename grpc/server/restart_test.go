@@ -0,0 +1,186 @@
+package server_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/rainbow-me/platform-tools/grpc/server"
+)
+
+// occupyPort returns a listener holding a local port, so that servers binding it fail.
+func occupyPort(t *testing.T) net.Listener {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = lis.Close() })
+	return lis
+}
+
+func TestServer_RestartOnFailure(t *testing.T) {
+	blocker := occupyPort(t)
+
+	srv, err := server.NewServer(
+		server.WithGRPCConfig(server.GRPCConfig{
+			Name:      "grpc",
+			Address:   blocker.Addr().String(),
+			SetupFunc: func(_ *grpc.Server) {},
+			Restart: server.RestartPolicy{
+				Mode:           server.RestartOnFailure,
+				InitialBackoff: 10 * time.Millisecond,
+				MaxBackoff:     20 * time.Millisecond,
+			},
+		}),
+		server.WithAutomaticStop(false),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+
+	// Not ready while the port is taken, ready once it is released
+	require.Eventually(t, func() bool { return len(srv.RestartEvents()) >= 2 }, time.Second, 5*time.Millisecond)
+	select {
+	case <-srv.Ready():
+		t.Fatal("server ready before listening")
+	default:
+	}
+	require.NoError(t, blocker.Close())
+
+	select {
+	case <-srv.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("server not ready after restarting")
+	}
+
+	events := srv.RestartEvents()
+	require.Equal(t, "grpc", events[0].Server)
+	require.Equal(t, 1, events[0].Restart)
+	require.Equal(t, 10*time.Millisecond, events[0].Backoff)
+	require.Equal(t, 20*time.Millisecond, events[1].Backoff)
+	require.ErrorContains(t, events[0].Err, "listen error")
+	require.False(t, events[len(events)-1].GaveUp)
+
+	conn, err := grpc.NewClient(srv.Addr("grpc").String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	res, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, res.GetStatus())
+
+	require.NoError(t, srv.GracefulShutdown(context.Background()))
+	require.NoError(t, <-done)
+}
+
+func TestServer_RestartGivesUp(t *testing.T) {
+	blocker := occupyPort(t)
+
+	srv, err := server.NewServer(
+		server.WithHTTPServer("http", blocker.Addr().String(), http.NewServeMux(),
+			server.WithHTTPRestartPolicy(server.RestartPolicy{
+				Mode:           server.RestartOnFailure,
+				MaxRestarts:    2,
+				InitialBackoff: time.Millisecond,
+			})),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+
+	select {
+	case err = <-done:
+		require.ErrorContains(t, err, "HTTP server http listen error")
+	case <-time.After(time.Second):
+		t.Fatal("server did not give up")
+	}
+
+	events := srv.RestartEvents()
+	require.Len(t, events, 3)
+	require.Equal(t, 1, events[0].Restart)
+	require.Equal(t, 2, events[1].Restart)
+	require.True(t, events[2].GaveUp)
+	require.Zero(t, events[2].Restart)
+}
+
+func TestServer_OptionalServerFailure(t *testing.T) {
+	blocker := occupyPort(t)
+
+	srv, err := server.NewServer(
+		server.WithHTTPServer("http", ":0", http.NewServeMux(), server.WithHTTPHealthProbes()),
+		server.WithAdminServer(blocker.Addr().String(), server.WithAdminHTTPOptions(
+			server.WithHTTPRestartPolicy(server.RestartPolicy{
+				Mode:           server.RestartOnFailure,
+				MaxRestarts:    1,
+				InitialBackoff: time.Millisecond,
+				Optional:       true,
+			}),
+		)),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+
+	select {
+	case <-srv.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("server not ready")
+	}
+	require.Eventually(t, func() bool {
+		events := srv.RestartEvents()
+		return len(events) == 2 && events[1].GaveUp
+	}, time.Second, 5*time.Millisecond)
+
+	// The main API keeps serving
+	resp, err := http.Get("http://" + srv.Addr("http").String() + server.ReadinessPath)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	select {
+	case err = <-done:
+		t.Fatalf("server stopped: %v", err)
+	default:
+	}
+
+	require.NoError(t, srv.GracefulShutdown(context.Background()))
+	require.NoError(t, <-done)
+}
+
+func TestServer_RestartPolicyValidation(t *testing.T) {
+	_, err := server.NewServer(server.WithGRPCConfig(server.GRPCConfig{
+		Name:       "grpc",
+		Address:    ":0",
+		GRPCServer: grpc.NewServer(),
+		Restart:    server.RestartOnFailureUpTo(3),
+	}))
+	require.ErrorContains(t, err, "cannot restart a GRPCServer")
+
+	lis := occupyPort(t)
+	_, err = server.NewServer(server.WithHTTPServer("http", "", http.NewServeMux(),
+		server.WithHTTPListener(lis), server.WithHTTPRestartPolicy(server.RestartOnFailureUpTo(3))))
+	require.ErrorContains(t, err, "cannot restart a pre-built listener")
+
+	// The admin server is restarted by default, except on a pre-built listener
+	_, err = server.NewServer(server.WithAdminServer("", server.WithAdminHTTPOptions(server.WithHTTPListener(lis))))
+	require.NoError(t, err)
+}
@@ -7,14 +7,16 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"sort"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/cockroachdb/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
 
 	"github.com/rainbow-me/platform-tools/common/logger"
 	"github.com/rainbow-me/platform-tools/grpc/gateway"
@@ -39,6 +41,19 @@ func WithShutdownTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithDrainDelay sets how long the servers keep serving after shutdown starts and before they stop.
+// During the drain phase the health checks report NOT_SERVING, so that load balancers stop routing new
+// requests, and HTTP responses carry "Connection: close". gRPC servers are gracefully stopped as the drain starts:
+// their clients receive GOAWAY and move to other instances while in-flight RPCs complete. Only the gRPC servers
+// behind an in-process gateway keep serving until the end of the drain. The delay counts against the shutdown
+// timeout.
+func WithDrainDelay(delay time.Duration) Option {
+	return func(s *Server) error {
+		s.drainDelay = delay
+		return nil
+	}
+}
+
 // WithHTTPConfig adds an HTTP server configuration
 func WithHTTPConfig(config HTTPConfig) Option {
 	return func(s *Server) error {
@@ -58,6 +73,20 @@ func WithHTTPConfig(config HTTPConfig) Option {
 		if config.Handler == nil {
 			return fmt.Errorf("HTTP config %s has no handler", config.Name)
 		}
+		if config.GRPC != nil && config.GRPC.SetupFunc == nil && config.GRPC.GRPCServer == nil {
+			return fmt.Errorf("HTTP config %s has a multiplexed gRPC server with no GRPCServer or SetupFunc", config.Name)
+		}
+		if config.MaxConnections < 0 || config.MaxHeaderBytes < 0 || config.MaxBodyBytes < 0 {
+			return fmt.Errorf("HTTP config %s has a negative connection, header or body limit", config.Name)
+		}
+		if config.Restart.Mode == RestartOnFailure {
+			if config.Listener != nil {
+				return fmt.Errorf("HTTP config %s cannot restart a pre-built listener", config.Name)
+			}
+			if config.GRPC != nil && config.GRPC.GRPCServer != nil {
+				return fmt.Errorf("HTTP config %s cannot restart a multiplexed GRPCServer, use SetupFunc instead", config.Name)
+			}
+		}
 		s.httpConfigs = append(s.httpConfigs, config)
 		return nil
 	}
@@ -68,12 +97,13 @@ func WithHTTPConfig(config HTTPConfig) Option {
 func WithHTTPServer(name, port string, handler http.Handler, opts ...HTTPConfigOption) Option {
 	config := HTTPConfig{
 		Name:    name,
-		Address: normalizePort(port),
+		Address: port,
 		Handler: handler,
 	}
 	for _, opt := range opts {
 		opt(&config)
 	}
+	config.Address = normalizeAddress(config.Network, config.Address)
 	return WithHTTPConfig(config)
 }
 
@@ -83,6 +113,24 @@ func WithGRPCConfig(config GRPCConfig) Option {
 		if config.SetupFunc == nil && config.GRPCServer == nil {
 			return fmt.Errorf("gRPC config %s has no GRPCServer or SetupFunc", config.Name)
 		}
+		if config.TLS != nil && config.GRPCServer != nil {
+			return fmt.Errorf("gRPC config %s has TLS and a GRPCServer, pass TLS.ServerOption() when creating it instead", config.Name)
+		}
+		if config.MaxConnections < 0 {
+			return fmt.Errorf("gRPC config %s has a negative connection limit", config.Name)
+		}
+		if config.MaxConcurrentStreams > 0 && config.GRPCServer != nil {
+			return fmt.Errorf("gRPC config %s has MaxConcurrentStreams and a GRPCServer, pass grpc.MaxConcurrentStreams "+
+				"when creating it instead", config.Name)
+		}
+		if config.Restart.Mode == RestartOnFailure {
+			if config.Listener != nil {
+				return fmt.Errorf("gRPC config %s cannot restart a pre-built listener", config.Name)
+			}
+			if config.GRPCServer != nil {
+				return fmt.Errorf("gRPC config %s cannot restart a GRPCServer, use SetupFunc instead", config.Name)
+			}
+		}
 		s.grpcConfigs = append(s.grpcConfigs, config)
 		return nil
 	}
@@ -119,15 +167,45 @@ func WithGateway(name, port string, httpOpts []HTTPConfigOption, gatewayOpts ...
 	}
 	config := HTTPConfig{
 		Name:    name,
-		Address: normalizePort(port),
+		Address: port,
 		Handler: mux,
 	}
 	for _, opt := range httpOpts {
 		opt(&config)
 	}
+	config.Address = normalizeAddress(config.Network, config.Address)
 	return WithHTTPConfig(config)
 }
 
+// WithInProcessGateway adds a dedicated HTTP server for the gRPC-REST gateway, like WithGateway, connected in memory
+// to the gRPC server named grpcName of this Server instead of over the network.
+func WithInProcessGateway(
+	name,
+	port,
+	grpcName string,
+	httpOpts []HTTPConfigOption,
+	gatewayOpts ...gateway.Option,
+) Option {
+	return func(s *Server) error {
+		lis, exists := s.inProcess[grpcName]
+		if !exists {
+			lis = newInProcessListener()
+			s.inProcess[grpcName] = lis
+		}
+		gatewayOpts = append(slices.Clip(gatewayOpts), gateway.WithInProcessListener(lis))
+		return WithGateway(name, port, httpOpts, gatewayOpts...)(s)
+	}
+}
+
+// WithLogLevelSignal toggles the level of the application logger between debug and its current level every time
+// one of the signals is received, e.g. syscall.SIGUSR1, see logger.ToggleDebug
+func WithLogLevelSignal(signals ...os.Signal) Option {
+	return func(s *Server) error {
+		s.logLevelSignals = append(s.logLevelSignals, signals...)
+		return nil
+	}
+}
+
 // WithAutomaticStop configures whether the server should automatically stop after the first error
 func WithAutomaticStop(isAutomaticStop bool) Option {
 	return func(s *Server) error {
@@ -144,6 +222,14 @@ func WithSignalHandling(enabled bool) Option {
 	}
 }
 
+// WithGRPCHealthService enables or disables the registration of the grpc_health_v1 service on every gRPC server
+func WithGRPCHealthService(enabled bool) Option {
+	return func(s *Server) error {
+		s.grpcHealthService = enabled
+		return nil
+	}
+}
+
 // WithShutdownHook adds a shutdown hook to be executed during graceful shutdown
 func WithShutdownHook(hook ShutdownHook) Option {
 	return func(s *Server) error {
@@ -155,6 +241,14 @@ func WithShutdownHook(hook ShutdownHook) Option {
 	}
 }
 
+// normalizeAddress normalizes TCP ports to ":port" format, unix socket paths are kept as is
+func normalizeAddress(network, address string) string {
+	if network == NetworkUnix {
+		return address
+	}
+	return normalizePort(address)
+}
+
 // normalizePort normalizes port to ":port" format
 func normalizePort(port string) string {
 	if port == "" {
@@ -170,24 +264,53 @@ func normalizePort(port string) string {
 // and shutdown hooks.
 type Server struct {
 	// Configuration
-	shutdownTimeout time.Duration  // Timeout for graceful shutdown
-	shutdownHooks   ShutdownHooks  // Cleanup functions to run during shutdown
-	httpConfigs     []HTTPConfig   // Configurations for HTTP servers
-	grpcConfigs     []GRPCConfig   // Configurations for gRPC servers
-	logger          *logger.Logger // Structured logger
-	signalHandling  bool           // Whether to handle OS signals
-	isAutomaticStop bool           // Whether to auto-stop on first error
+	shutdownTimeout   time.Duration  // Timeout for graceful shutdown
+	drainDelay        time.Duration  // Time to keep serving after shutdown starts, before stopping servers
+	shutdownHooks     ShutdownHooks  // Cleanup functions to run during shutdown
+	startupHooks      StartupHooks   // Functions to run before listeners open
+	reloadHooks       ReloadHooks    // Functions to run on reload
+	httpConfigs       []HTTPConfig   // Configurations for HTTP servers
+	grpcConfigs       []GRPCConfig   // Configurations for gRPC servers
+	workerConfigs     []WorkerConfig // Configurations for background workers
+	logger            *logger.Logger // Structured logger
+	signalHandling    bool           // Whether to handle OS signals
+	isAutomaticStop   bool           // Whether to auto-stop on first error
+	grpcHealthService bool           // Whether to register grpc_health_v1 on every gRPC server
+	logLevelSignals   []os.Signal    // Signals toggling the debug log level
+	reloadSignals     []os.Signal    // Signals triggering a reload instead of a shutdown
 
 	// Runtime state
-	httpServers    map[string]*http.Server // Running HTTP servers by name
-	grpcServers    map[string]*grpc.Server // Running gRPC servers by name
-	serverMu       sync.RWMutex            // Protects httpServers and grpcServers
-	shutdownCtx    context.Context         // Context for coordinating shutdown
-	shutdownCancel context.CancelFunc      // Function to trigger shutdown
-	shutdownOnce   sync.Once               // Ensures shutdown only happens once
-	wg             sync.WaitGroup          // Tracks running server goroutines
-	errChan        chan error              // Channel for collecting server errors
-	signalChan     chan os.Signal          // Channel for OS signals
+	httpServers    map[string]*http.Server       // Running HTTP servers by name
+	grpcServers    map[string]*grpc.Server       // Running gRPC servers by name
+	multiplexed    map[string]*multiplexedServer // gRPC servers sharing the listener of an HTTP server, by name
+	serverMu       sync.RWMutex                  // Protects httpServers, grpcServers and multiplexed
+	shutdownCtx    context.Context               // Context for coordinating shutdown
+	shutdownCancel context.CancelFunc            // Function to trigger shutdown
+	shutdownOnce   sync.Once                     // Ensures shutdown only happens once
+	wg             sync.WaitGroup                // Tracks running server goroutines
+	workersWG      sync.WaitGroup                // Tracks running workers
+	errChan        chan error                    // Channel for collecting server errors
+	signalChan     chan os.Signal                // Channel for OS signals
+	health         *Health                       // Serving status reported by health checks
+	listenWG       sync.WaitGroup                // Tracks servers that have not tried to listen yet
+	listenFailed   atomic.Bool                   // Whether a server failed to listen
+	addrs          map[string]net.Addr           // Bound addresses by server name, protected by serverMu
+	ready          chan struct{}                 // Closed once every server is listening
+	startupDone    chan struct{}                 // Closed once every server is listening or failed to
+	done           chan struct{}                 // Closed when Serve returns
+	lifecycle      *lifecycle                    // State of the servers and event listeners
+	conns          map[string]*connStats         // Connection counters by server name, filled by NewServer
+	draining       atomic.Bool                   // Whether the drain phase has started
+	inProcess      map[string]*inProcessListener // In-memory listeners of the gRPC servers, by gRPC server name
+	adminMux       *http.ServeMux                // Routes of the admin server, nil without WithAdminServer
+	restartMu      sync.Mutex                    // Protects restartEvents
+	restartEvents  []RestartEvent                // Latest failures of the servers, oldest first
+
+	hooksMu               sync.Mutex          // Protects completedStartupHooks, shutdownReport and reloadReport
+	completedStartupHooks map[string]struct{} // Names of the startup hooks that completed
+	shutdownReport        *ShutdownReport     // Result of the last execution of the shutdown hooks
+	reloadReport          *ReloadReport       // Result of the last reload
+	reloadMu              sync.Mutex          // Serializes reloads
 }
 
 // NewServer creates a Server from the given options.
@@ -197,16 +320,28 @@ func NewServer(opts ...Option) (*Server, error) {
 		return nil, errors.Wrap(err, "failed to create logger")
 	}
 	s := &Server{
-		shutdownTimeout: DefaultShutdownTimeout,
-		httpConfigs:     []HTTPConfig{},
-		grpcConfigs:     []GRPCConfig{},
-		httpServers:     make(map[string]*http.Server),
-		grpcServers:     make(map[string]*grpc.Server),
-		isAutomaticStop: true,
-		signalHandling:  true,
-		logger:          log,
-		errChan:         make(chan error, 20),
-		signalChan:      make(chan os.Signal, 5),
+		shutdownTimeout:   DefaultShutdownTimeout,
+		httpConfigs:       []HTTPConfig{},
+		grpcConfigs:       []GRPCConfig{},
+		httpServers:       make(map[string]*http.Server),
+		grpcServers:       make(map[string]*grpc.Server),
+		multiplexed:       make(map[string]*multiplexedServer),
+		inProcess:         make(map[string]*inProcessListener),
+		isAutomaticStop:   true,
+		signalHandling:    true,
+		grpcHealthService: true,
+		logger:            log,
+		errChan:           make(chan error, 20),
+		signalChan:        make(chan os.Signal, 5),
+		health:            newHealth(),
+		addrs:             make(map[string]net.Addr),
+		ready:             make(chan struct{}),
+		startupDone:       make(chan struct{}),
+		done:              make(chan struct{}),
+		lifecycle:         newLifecycle(),
+		conns:             make(map[string]*connStats),
+
+		completedStartupHooks: make(map[string]struct{}),
 	}
 
 	s.shutdownCtx, s.shutdownCancel = context.WithCancel(context.Background())
@@ -218,6 +353,10 @@ func NewServer(opts ...Option) (*Server, error) {
 		}
 	}
 
+	if err = validateShutdownHooks(s.shutdownHooks); err != nil {
+		return nil, err
+	}
+
 	// Validate configs
 	nameSet := make(map[string]struct{})
 	addrSet := make(map[string]struct{})
@@ -227,11 +366,11 @@ func NewServer(opts ...Option) (*Server, error) {
 			return nil, fmt.Errorf("duplicate HTTP server name: %s", config.Name)
 		}
 		nameSet[config.Name] = struct{}{}
-		if config.Address != "" {
-			if _, exists := addrSet[config.Address]; exists {
+		if key, ok := bindKey(config.Listener, config.Network, config.Address); ok {
+			if _, exists := addrSet[key]; exists {
 				return nil, fmt.Errorf("duplicate bind address: %s", config.Address)
 			}
-			addrSet[config.Address] = struct{}{}
+			addrSet[key] = struct{}{}
 		}
 		if config.Handler == nil {
 			return nil, fmt.Errorf("HTTP server %s has no handler", config.Name)
@@ -243,24 +382,52 @@ func NewServer(opts ...Option) (*Server, error) {
 			return nil, fmt.Errorf("duplicate gRPC server name: %s", config.Name)
 		}
 		nameSet[config.Name] = struct{}{}
-		if config.Address != "" {
-			if _, exists := addrSet[config.Address]; exists {
+		if key, ok := bindKey(config.Listener, config.Network, config.Address); ok {
+			if _, exists := addrSet[key]; exists {
 				return nil, fmt.Errorf("duplicate bind address: %s", config.Address)
 			}
-			addrSet[config.Address] = struct{}{}
+			addrSet[key] = struct{}{}
 		}
 		if config.SetupFunc == nil && config.GRPCServer == nil {
 			return nil, fmt.Errorf("gRPC server %s has no GRPCServer or SetupFunc", config.Name)
 		}
 	}
 
+	for _, config := range s.workerConfigs {
+		if _, exists := nameSet[config.Name]; exists {
+			return nil, fmt.Errorf("duplicate worker name: %s", config.Name)
+		}
+		nameSet[config.Name] = struct{}{}
+	}
+
+	for grpcName := range s.inProcess {
+		if !slices.ContainsFunc(s.grpcConfigs, func(config GRPCConfig) bool { return config.Name == grpcName }) {
+			return nil, fmt.Errorf("in-process gateway connects to unknown gRPC server: %s", grpcName)
+		}
+	}
+
+	for _, config := range s.httpConfigs {
+		s.lifecycle.add(config.Name, KindHTTP)
+		s.conns[config.Name] = &connStats{}
+	}
+	for _, config := range s.grpcConfigs {
+		s.lifecycle.add(config.Name, KindGRPC)
+		s.conns[config.Name] = &connStats{}
+	}
+	for _, config := range s.workerConfigs {
+		s.lifecycle.add(config.Name, KindWorker)
+	}
+
 	s.logger.Info("Server created successfully",
 		logger.Duration("shutdown_timeout", s.shutdownTimeout),
 		logger.Bool("signal_handling", s.signalHandling),
 		logger.Bool("automatic_stop", s.isAutomaticStop),
 		logger.Int("http_server_count", len(s.httpConfigs)),
 		logger.Int("grpc_server_count", len(s.grpcConfigs)),
+		logger.Int("worker_count", len(s.workerConfigs)),
 		logger.Int("shutdown_hooks", len(s.shutdownHooks)),
+		logger.Int("startup_hooks", len(s.startupHooks)),
+		logger.Int("reload_hooks", len(s.reloadHooks)),
 	)
 
 	return s, nil
@@ -268,13 +435,31 @@ func NewServer(opts ...Option) (*Server, error) {
 
 // Serve starts all configured servers
 func (s *Server) Serve() error {
-	if len(s.httpConfigs) == 0 && len(s.grpcConfigs) == 0 {
+	if len(s.httpConfigs) == 0 && len(s.grpcConfigs) == 0 && len(s.workerConfigs) == 0 {
 		return errors.New("no servers configured")
 	}
 
 	if s.signalHandling {
 		s.setupSignalHandling()
 	}
+	if len(s.logLevelSignals) > 0 {
+		logger.ToggleDebugOnSignal(s.shutdownCtx, s.logLevelSignals...)
+	}
+	if len(s.reloadSignals) > 0 {
+		s.handleReloadSignals()
+	}
+
+	// Run startup hooks before binding any port; a signal cancels them
+	if err := s.executeStartupHooks(s.shutdownCtx); err != nil {
+		err = s.abortStartup(err)
+		close(s.errChan)
+		signal.Stop(s.signalChan)
+		close(s.signalChan)
+		s.stopped()
+		return err
+	}
+
+	s.listenWG.Add(len(s.httpConfigs) + len(s.grpcConfigs) + len(s.workerConfigs))
 
 	// Start HTTP servers
 	for _, config := range s.httpConfigs {
@@ -286,8 +471,26 @@ func (s *Server) Serve() error {
 		s.startGRPCServer(config)
 	}
 
+	// Start workers
+	for _, config := range s.workerConfigs {
+		s.startWorker(config)
+	}
+
 	s.logger.Info("All servers started")
 
+	// Report SERVING once every listener is up
+	go func() {
+		s.listenWG.Wait()
+		defer close(s.startupDone)
+		if s.listenFailed.Load() {
+			return
+		}
+		close(s.ready)
+		s.health.markReady()
+		s.logger.Info("All servers listening, health status set to SERVING")
+		s.emit(Event{Type: EventStarted})
+	}()
+
 	var errs []error
 	select {
 	case sig := <-s.signalChan:
@@ -314,13 +517,26 @@ func (s *Server) Serve() error {
 	close(s.errChan)
 	signal.Stop(s.signalChan) // Stop signal notifications before closing channel
 	close(s.signalChan)
+	s.stopped()
 
 	return errors.Join(errs...)
 }
 
+// stopped reports that every server stopped, when Serve returns.
+func (s *Server) stopped() {
+	s.markNotStarted()
+	s.emit(Event{Type: EventStopped})
+	close(s.done)
+}
+
+// Health returns the health status of the server, used to flip the status of individual services
+func (s *Server) Health() *Health {
+	return s.health
+}
+
 // setupSignalHandling configures signal handlers for graceful shutdown
 func (s *Server) setupSignalHandling() {
-	signal.Notify(s.signalChan, os.Interrupt, syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM)
+	signal.Notify(s.signalChan, s.shutdownSignals()...)
 
 	go func() {
 		for sig := range s.signalChan {
@@ -333,95 +549,236 @@ func (s *Server) setupSignalHandling() {
 	s.logger.Debug("Signal handling configured")
 }
 
-// startHTTPServer starts a single HTTP server in a goroutine
+// shutdownSignals returns the signals shutting the Server down, without the reload signals.
+func (s *Server) shutdownSignals() []os.Signal {
+	signals := []os.Signal{os.Interrupt, syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM}
+	return slices.DeleteFunc(signals, func(sig os.Signal) bool {
+		return slices.Contains(s.reloadSignals, sig)
+	})
+}
+
+// startHTTPServer starts a single HTTP server in a goroutine, restarting it according to its policy
 func (s *Server) startHTTPServer(config HTTPConfig) {
-	s.wg.Add(1)
-	go func() {
-		defer s.wg.Done()
+	s.supervise(KindHTTP, config.Name, config.Restart, func(listening func()) error {
+		return s.runHTTPServer(config, listening)
+	})
+}
 
-		server := &http.Server{
-			Addr:              config.Address,
-			Handler:           config.Handler,
-			ReadTimeout:       config.ReadTimeout,
-			ReadHeaderTimeout: config.HeaderTimeout,
-			WriteTimeout:      config.WriteTimeout,
-			IdleTimeout:       config.IdleTimeout,
-		}
+// runHTTPServer serves a new instance of the HTTP server until it stops. It returns nil when the server was
+// closed by the Server.
+func (s *Server) runHTTPServer(config HTTPConfig, listening func()) (err error) {
+	handler := s.closeConnectionsWhenDraining(config.Handler)
+	if config.MaxBodyBytes > 0 {
+		handler = limitBody(handler, config.MaxBodyBytes)
+	}
+	handler = rejectOverloaded(handler)
+	if config.HealthProbes {
+		handler = s.health.withProbes(handler) // Probes keep answering when overloaded
+	}
 
-		s.serverMu.Lock()
+	server := &http.Server{
+		Addr:              config.Address,
+		Handler:           handler,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.HeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+		ConnContext:       markRejectedConn,
+	}
+	if config.TLS != nil {
+		server.TLSConfig = config.TLS.ServerTLS()
+	}
+	var mux *multiplexedServer
+	var registered bool
+	if config.GRPC != nil {
+		if mux, server.Handler, err = s.multiplex(config, server, handler); err != nil {
+			s.logger.Error("Failed to configure HTTP/2", logger.String("name", config.Name), logger.Error(err))
+			return fmt.Errorf("HTTP server %s HTTP/2 error: %w", config.Name, err)
+		}
+		// On failure, release the gRPC server: it cannot serve again once stopped, a restart creates a new one.
+		// Otherwise shutdown stops it once its requests complete.
+		defer func() {
+			if err != nil || !registered {
+				mux.grpc.Stop()
+			}
+		}()
+	}
+
+	registered = s.register(func() {
 		s.httpServers[config.Name] = server
-		s.serverMu.Unlock()
+		if mux != nil {
+			s.multiplexed[config.Name] = mux
+		}
+	})
+	if !registered {
+		return nil // Shutting down
+	}
 
-		s.logger.Info("Starting HTTP server", logger.String("name", config.Name), logger.String("address", config.Address))
+	s.logger.Info("Starting HTTP server", logger.String("name", config.Name), logger.String("address", config.Address))
 
-		lis, err := net.Listen("tcp", config.Address)
-		if err != nil {
-			s.logger.Error("Failed to listen", logger.String("name", config.Name), logger.Error(err))
-			s.errChan <- fmt.Errorf("HTTP server %s listen error: %w", config.Name, err)
-			return
-		}
+	lis, err := s.listen(config.Name, config.Listener, config.Network, config.Address)
+	if err != nil {
+		s.logger.Error("Failed to listen", logger.String("name", config.Name), logger.Error(err))
+		return fmt.Errorf("HTTP server %s listen error: %w", config.Name, err)
+	}
+	lis = &limitListener{Listener: lis, max: int64(config.MaxConnections), stats: s.conns[config.Name]}
+	listening()
 
+	if config.TLS != nil {
+		err = server.ServeTLS(lis, "", "") // Certificates come from TLSConfig
+	} else {
 		err = server.Serve(lis)
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			s.logger.Error("HTTP server error", logger.String("name", config.Name), logger.Error(err))
-			s.errChan <- fmt.Errorf("HTTP server %s error: %w", config.Name, err)
-		} else {
-			s.logger.Info("HTTP server stopped", logger.String("name", config.Name))
-		}
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.logger.Error("HTTP server error", logger.String("name", config.Name), logger.Error(err))
+		err = fmt.Errorf("HTTP server %s error: %w", config.Name, err)
+	} else {
+		s.logger.Info("HTTP server stopped", logger.String("name", config.Name))
+		err = nil
+	}
+
+	// Close listener
+	if cerr := lis.Close(); cerr != nil && !errors.Is(cerr, net.ErrClosed) {
+		s.logger.Warn("Error closing listener", logger.String("name", config.Name), logger.Error(cerr))
+	}
+	return err
+}
 
-		// Close listener
-		if cerr := lis.Close(); cerr != nil && !errors.Is(cerr, net.ErrClosed) {
-			s.logger.Warn("Error closing listener", logger.String("name", config.Name), logger.Error(cerr))
+// startGRPCServer starts a single gRPC server in a goroutine, restarting it according to its policy
+func (s *Server) startGRPCServer(config GRPCConfig) {
+	s.supervise(KindGRPC, config.Name, config.Restart, func(listening func()) error {
+		return s.runGRPCServer(config, listening)
+	})
+}
+
+// runGRPCServer serves a new instance of the gRPC server until it stops. It returns nil when the server was
+// stopped by the Server.
+func (s *Server) runGRPCServer(config GRPCConfig, listening func()) (err error) {
+	server := s.newGRPCServer(config)
+	// On failure, release the server: it cannot serve again once stopped, a restart creates a new one.
+	// Otherwise shutdown stops it gracefully.
+	defer func() {
+		if err != nil {
+			server.Stop()
 		}
 	}()
+
+	if !s.register(func() { s.grpcServers[config.Name] = server }) {
+		server.Stop()
+		return nil // Shutting down
+	}
+
+	s.logger.Info("Starting gRPC server", logger.String("name", config.Name), logger.String("address", config.Address))
+
+	lis, err := s.listen(config.Name, config.Listener, config.Network, config.Address)
+	if err != nil {
+		s.logger.Error("Failed to listen", logger.String("name", config.Name), logger.Error(err))
+		return fmt.Errorf("gRPC server %s listen error: %w", config.Name, err)
+	}
+	lis = &limitListener{Listener: lis, max: int64(config.MaxConnections), stats: s.conns[config.Name],
+		closeRejected: true}
+	listening()
+
+	if inProcessLis, ok := s.inProcess[config.Name]; ok {
+		s.serveInProcess(config.Name, server, inProcessLis.next())
+	}
+
+	err = server.Serve(lis)
+	if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		s.logger.Error("gRPC server error", logger.String("name", config.Name), logger.Error(err))
+		err = fmt.Errorf("gRPC server %s error: %w", config.Name, err)
+	} else {
+		s.logger.Info("gRPC server stopped", logger.String("name", config.Name))
+		err = nil
+	}
+
+	// Close listener
+	if cerr := lis.Close(); cerr != nil && !errors.Is(cerr, net.ErrClosed) {
+		s.logger.Warn("Error closing listener", logger.String("name", config.Name), logger.Error(cerr))
+	}
+	return err
 }
 
-// startGRPCServer starts a single gRPC server in a goroutine
-func (s *Server) startGRPCServer(config GRPCConfig) {
+// register records a new instance of a server with add, under serverMu, unless the Server is shutting down.
+// Shutdown starts by cancelling shutdownCtx, so every instance it does not stop is refused here.
+func (s *Server) register(add func()) bool {
+	s.serverMu.Lock()
+	defer s.serverMu.Unlock()
+	if s.shutdownCtx.Err() != nil {
+		return false
+	}
+	add()
+	return true
+}
+
+// serveInProcess serves server on the in-memory listener of the gateways connected to it. The listener is
+// closed when the server stops.
+func (s *Server) serveInProcess(name string, server *grpc.Server, lis *bufconn.Listener) {
 	s.wg.Add(1)
 	go func() {
 		defer s.wg.Done()
-
-		// Use existing gRPC server if provided, else create new
-		var server *grpc.Server
-		if config.GRPCServer != nil {
-			server = config.GRPCServer
-		} else {
-			server = grpc.NewServer(config.GRPCOpts...)
+		if err := server.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			s.logger.Error("gRPC in-process listener error", logger.String("name", name), logger.Error(err))
 		}
+	}()
+}
 
-		s.serverMu.Lock()
-		s.grpcServers[config.Name] = server
-		s.serverMu.Unlock()
+// inProcessListener is the in-memory listener the gateways dial to reach a gRPC server. Stopping the gRPC server
+// closes it, so a restarted server serves a new one.
+type inProcessListener struct {
+	mu     sync.Mutex
+	lis    *bufconn.Listener
+	served bool
+}
 
-		// Register services if setup func provided
-		if config.SetupFunc != nil {
-			s.logger.Debug("Setting up gRPC services", logger.String("name", config.Name))
-			config.SetupFunc(server)
-		}
+func newInProcessListener() *inProcessListener {
+	return &inProcessListener{lis: bufconn.Listen(inProcessBufferSize)}
+}
 
-		s.logger.Info("Starting gRPC server", logger.String("name", config.Name), logger.String("address", config.Address))
+// DialContext connects to the listener currently served.
+func (l *inProcessListener) DialContext(ctx context.Context) (net.Conn, error) {
+	l.mu.Lock()
+	lis := l.lis
+	l.mu.Unlock()
+	return lis.DialContext(ctx)
+}
 
-		lis, err := net.Listen("tcp", config.Address)
-		if err != nil {
-			s.logger.Error("Failed to listen", logger.String("name", config.Name), logger.Error(err))
-			s.errChan <- fmt.Errorf("gRPC server %s listen error: %w", config.Name, err)
-			return
-		}
+// next returns the listener for a new instance of the gRPC server, replacing the one of the previous instance.
+func (l *inProcessListener) next() *bufconn.Listener {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.served {
+		_ = l.lis.Close() // Fails pending dials, so that clients reconnect to the new listener
+		l.lis = bufconn.Listen(inProcessBufferSize)
+	}
+	l.served = true
+	return l.lis
+}
 
-		err = server.Serve(lis)
-		if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
-			s.logger.Error("gRPC server error", logger.String("name", config.Name), logger.Error(err))
-			s.errChan <- fmt.Errorf("gRPC server %s error: %w", config.Name, err)
-		} else {
-			s.logger.Info("gRPC server stopped", logger.String("name", config.Name))
+// newGRPCServer returns the existing gRPC server of config or creates it, then registers its services.
+func (s *Server) newGRPCServer(config GRPCConfig) *grpc.Server {
+	server := config.GRPCServer
+	if server == nil {
+		opts := config.GRPCOpts
+		if config.TLS != nil {
+			opts = append(slices.Clip(opts), config.TLS.ServerOption())
 		}
-
-		// Close listener
-		if cerr := lis.Close(); cerr != nil && !errors.Is(cerr, net.ErrClosed) {
-			s.logger.Warn("Error closing listener", logger.String("name", config.Name), logger.Error(cerr))
+		if config.MaxConcurrentStreams > 0 {
+			opts = append(slices.Clip(opts), grpc.MaxConcurrentStreams(config.MaxConcurrentStreams))
 		}
-	}()
+		server = grpc.NewServer(opts...)
+	}
+
+	// Register services if setup func provided
+	if config.SetupFunc != nil {
+		s.logger.Debug("Setting up gRPC services", logger.String("name", config.Name))
+		config.SetupFunc(server)
+	}
+	if s.grpcHealthService {
+		s.health.register(server)
+	}
+	return server
 }
 
 // Stop immediately terminates all servers
@@ -447,8 +804,26 @@ func (s *Server) GracefulShutdown(ctx context.Context) error {
 func (s *Server) shutdown(ctx context.Context, isGraceful bool) error { //nolint:gocognit
 	var shutdownErr error
 	s.shutdownOnce.Do(func() {
+		if isGraceful && s.drainDelay > 0 {
+			// Set before the status changes, so that responses are seen closing connections once it is draining
+			s.draining.Store(true)
+		}
+		// Fail health checks first, so that load balancers stop routing new requests
+		s.health.markShuttingDown()
+		s.markDraining()
+		s.emit(Event{Type: EventStopping})
+
 		var wg sync.WaitGroup
+		s.serverMu.RLock()
 		errC := make(chan error, len(s.httpServers)+len(s.grpcServers))
+		s.serverMu.RUnlock()
+
+		if isGraceful {
+			// gRPC clients receive GOAWAY as the drain starts and send their new RPCs to other instances, while
+			// in-flight RPCs complete. Servers behind an in-process gateway keep serving it during the drain.
+			s.stopGRPCServers(ctx, &wg, errC, true, s.servesDirectly)
+			s.drain(ctx)
+		}
 
 		shutdownType := "immediate"
 		if isGraceful {
@@ -468,6 +843,7 @@ func (s *Server) shutdown(ctx context.Context, isGraceful bool) error { //nolint
 				} else {
 					err = srv.Close()
 				}
+				err = errors.Join(err, s.stopMultiplexed(ctx, n, isGraceful))
 				if err != nil {
 					errC <- fmt.Errorf("HTTP server %s shutdown error: %w", n, err)
 				}
@@ -475,30 +851,10 @@ func (s *Server) shutdown(ctx context.Context, isGraceful bool) error { //nolint
 		}
 		s.serverMu.RUnlock()
 
-		// Shutdown gRPC servers
-		s.serverMu.RLock()
-		for name, server := range s.grpcServers {
-			wg.Add(1)
-			go func(n string, srv *grpc.Server) {
-				defer wg.Done()
-				if isGraceful {
-					done := make(chan struct{})
-					go func() {
-						srv.GracefulStop()
-						close(done)
-					}()
-					select {
-					case <-done:
-					case <-ctx.Done():
-						srv.Stop()
-						errC <- fmt.Errorf("gRPC server %s graceful shutdown timed out", n)
-					}
-				} else {
-					srv.Stop()
-				}
-			}(name, server)
-		}
-		s.serverMu.RUnlock()
+		// Shutdown the remaining gRPC servers
+		s.stopGRPCServers(ctx, &wg, errC, isGraceful, func(name string) bool {
+			return !isGraceful || !s.servesDirectly(name)
+		})
 
 		// Wait for completion
 		done := make(chan struct{})
@@ -514,17 +870,25 @@ func (s *Server) shutdown(ctx context.Context, isGraceful bool) error { //nolint
 			errs = append(errs, ErrShutdownTimeout)
 		}
 
-		close(errC)
-		for err := range errC {
-			if err != nil {
+		// On timeout servers may still be stopping: collect the errors reported so far without closing errC,
+		// whose buffer holds one error per server
+	collect:
+		for {
+			select {
+			case err := <-errC:
 				errs = append(errs, err)
+			default:
+				break collect
 			}
 		}
 
 		shutdownErr = errors.Join(errs...)
 
-		// Execute hooks if graceful
+		// Execute hooks if graceful, once the workers returned since hooks may close what they use
 		if isGraceful {
+			if err := s.waitWorkers(ctx); err != nil {
+				shutdownErr = errors.Join(shutdownErr, err)
+			}
 			hookErr := s.ExecuteShutdownHooks(ctx)
 			if hookErr != nil {
 				shutdownErr = errors.Join(shutdownErr, hookErr)
@@ -535,70 +899,82 @@ func (s *Server) shutdown(ctx context.Context, isGraceful bool) error { //nolint
 	return shutdownErr
 }
 
-// ExecuteShutdownHooks executes all registered shutdown hooks in priority order
-func (s *Server) ExecuteShutdownHooks(ctx context.Context) error {
-	if len(s.shutdownHooks) == 0 {
-		return nil
+// drain keeps serving HTTP for the configured drain delay, or until ctx is done, while asking HTTP clients to close
+// their connections. The gRPC servers serving clients directly are already gracefully stopping.
+func (s *Server) drain(ctx context.Context) {
+	if s.drainDelay <= 0 {
+		return
 	}
 
-	s.logger.Info("Executing shutdown hooks", logger.Int("count", len(s.shutdownHooks)))
+	s.logger.Info("Draining servers before shutdown", logger.Duration("delay", s.drainDelay))
 
-	sort.Sort(s.shutdownHooks)
+	s.serverMu.RLock()
+	for _, server := range s.httpServers {
+		// Closes idle connections and sends "Connection: close" on HTTP/1.1 responses
+		server.SetKeepAlivesEnabled(false)
+	}
+	s.serverMu.RUnlock()
 
-	var wg sync.WaitGroup
-	var hookErrs []error
-	var mu sync.Mutex // For safe append
+	timer := time.NewTimer(s.drainDelay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		s.logger.Info("Drain completed")
+	case <-ctx.Done():
+		s.logger.Warn("Drain interrupted by shutdown timeout")
+	}
+}
 
-	for _, hook := range s.shutdownHooks {
+// stopGRPCServers stops the gRPC servers selected by include in goroutines tracked by wg, reporting errors on errC.
+// A graceful stop sends GOAWAY, refuses new connections and waits for in-flight RPCs until ctx is done.
+func (s *Server) stopGRPCServers(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	errC chan<- error,
+	isGraceful bool,
+	include func(name string) bool,
+) {
+	s.serverMu.RLock()
+	defer s.serverMu.RUnlock()
+	for name, server := range s.grpcServers {
+		if !include(name) {
+			continue
+		}
 		wg.Add(1)
-		go func(h ShutdownHook) {
+		go func(n string, srv *grpc.Server) {
 			defer wg.Done()
-			hookCtx, cancel := context.WithTimeout(ctx, h.Timeout)
-			defer cancel()
-
-			s.logger.Info("Executing hook", logger.String("name", h.Name), logger.Int("priority", h.Priority))
-
-			start := time.Now()
-			err := h.Hook(hookCtx)
+			if !isGraceful {
+				srv.Stop()
+				return
+			}
+			done := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(done)
+			}()
 			select {
-			case <-hookCtx.Done():
-				if errors.Is(hookCtx.Err(), context.DeadlineExceeded) {
-					mu.Lock()
-					hookErrs = append(hookErrs, fmt.Errorf("hook %s timed out", h.Name))
-					mu.Unlock()
-					s.logger.Error("Hook timed out", logger.String("name", h.Name))
-					return // Exit the goroutine early on timeout
-				}
-			default:
-				if err != nil {
-					mu.Lock()
-					hookErrs = append(hookErrs, err)
-					mu.Unlock()
-					s.logger.Error("Hook failed", logger.String("name", h.Name), logger.Error(err))
-				} else {
-					s.logger.Info("Hook completed", logger.String("name", h.Name), logger.Duration("duration", time.Since(start)))
-				}
+			case <-done:
+			case <-ctx.Done():
+				srv.Stop()
+				errC <- fmt.Errorf("gRPC server %s graceful shutdown timed out", n)
 			}
-		}(hook)
-	}
-
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
-
-	select {
-	case <-done:
-	case <-ctx.Done():
-		return ErrShutdownTimeout // Overall shutdown timeout for all hooks
+		}(name, server)
 	}
+}
 
-	if len(hookErrs) > 0 {
-		s.logger.Error("Some shutdown hooks failed", logger.Error(errors.Join(hookErrs...)))
-		return errors.Join(hookErrs...)
-	}
+// servesDirectly reports whether the named gRPC server serves no in-process gateway, so that it can stop as
+// the drain starts.
+func (s *Server) servesDirectly(name string) bool {
+	_, inProcess := s.inProcess[name]
+	return !inProcess
+}
 
-	s.logger.Info("All shutdown hooks completed")
-	return nil
+// closeConnectionsWhenDraining asks clients to close their connection on responses sent during the drain phase.
+func (s *Server) closeConnectionsWhenDraining(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.draining.Load() {
+			w.Header().Set("Connection", "close")
+		}
+		next.ServeHTTP(w, r)
+	})
 }
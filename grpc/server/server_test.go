@@ -323,7 +323,7 @@ func TestServer_Stop(t *testing.T) {
 		done <- srv.Serve()
 	}()
 
-	time.Sleep(100 * time.Millisecond) // Give time to start
+	require.NoError(t, srv.WaitReady(context.Background()))
 	err = srv.Stop()
 	if err != nil {
 		t.Errorf("Stop() error = %v", err)
@@ -355,7 +355,7 @@ func TestServer_GracefulShutdown(t *testing.T) {
 		done <- srv.Serve()
 	}()
 
-	time.Sleep(100 * time.Millisecond) // Give time to start
+	require.NoError(t, srv.WaitReady(context.Background()))
 	err = srv.GracefulShutdown(context.Background())
 	if err != nil {
 		t.Errorf("GracefulShutdown() error = %v", err)
@@ -420,7 +420,7 @@ func TestSignalHandling(t *testing.T) {
 		done <- srv.Serve()
 	}()
 
-	time.Sleep(100 * time.Millisecond) // Give time to start
+	require.NoError(t, srv.WaitReady(context.Background()))
 	err = syscall.Kill(os.Getpid(), syscall.SIGTERM)
 	require.NoError(t, err, "Failed to send SIGTERM")
 
@@ -507,7 +507,7 @@ func TestGRPCServerAndClient(t *testing.T) {
 				done <- srv.Serve()
 			}()
 
-			time.Sleep(time.Second) // Wait for startup
+			require.NoError(t, srv.WaitReady(context.Background()))
 
 			// gRPC client
 			conn, err := grpc.NewClient("localhost"+grpcPort, grpc.WithTransportCredentials(insecure.NewCredentials()))
@@ -584,7 +584,7 @@ func TestGRPCGateway(t *testing.T) {
 				done <- srv.Serve()
 			}()
 
-			time.Sleep(time.Second) // Wait for startup
+			require.NoError(t, srv.WaitReady(context.Background()))
 
 			// Test via HTTP (gateway)
 			url := "http://localhost" + gatewayPort + "/test/hello"
@@ -0,0 +1,320 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/rainbow-me/platform-tools/common/logger"
+)
+
+// HookResult is the outcome of a single shutdown hook
+type HookResult struct {
+	Name     string
+	Priority int
+	Duration time.Duration // Time spent running the hook, excluding the wait for its dependencies
+	Err      error         // Hook error; wraps ErrHookTimeout when the hook timed out
+	Skipped  bool          // The hook did not run: its startup hook did not complete or the shutdown timed out first
+}
+
+// ShutdownReport is the outcome of the shutdown hooks, in execution order
+type ShutdownReport struct {
+	Hooks    []HookResult
+	Duration time.Duration // Total time spent running the hooks
+	TimedOut bool          // The overall shutdown timeout expired before all hooks completed
+}
+
+// Err returns the errors of the failed hooks, joined with ErrShutdownTimeout if the overall timeout expired.
+func (r *ShutdownReport) Err() error {
+	var errs []error
+	for _, hook := range r.Hooks {
+		if hook.Err != nil {
+			errs = append(errs, fmt.Errorf("hook %s: %w", hook.Name, hook.Err))
+		}
+	}
+	if r.TimedOut {
+		errs = append(errs, ErrShutdownTimeout)
+	}
+	return errors.Join(errs...)
+}
+
+// ExecuteShutdownHooks executes all registered shutdown hooks, see RunShutdownHooks
+func (s *Server) ExecuteShutdownHooks(ctx context.Context) error {
+	return s.RunShutdownHooks(ctx).Err()
+}
+
+// RunShutdownHooks executes all registered shutdown hooks in priority groups and returns the structured result.
+// Hooks with equal priority run in parallel and each group waits for the previous one; within a group, a hook
+// also waits for the hooks listed in its DependsOn. Each hook keeps its own timeout, ctx bounds the whole run.
+// Hooks whose startup hook did not complete are skipped.
+func (s *Server) RunShutdownHooks(ctx context.Context) *ShutdownReport {
+	report := &ShutdownReport{}
+	if len(s.shutdownHooks) == 0 {
+		return report
+	}
+
+	s.logger.Info("Executing shutdown hooks", logger.Int("count", len(s.shutdownHooks)))
+
+	hooks := make(ShutdownHooks, len(s.shutdownHooks))
+	copy(hooks, s.shutdownHooks)
+	sort.Stable(hooks)
+
+	run := newHookRun(hooks)
+	start := time.Now()
+
+	for groupStart := 0; groupStart < len(hooks); {
+		groupEnd := groupStart
+		for groupEnd < len(hooks) && hooks[groupEnd].Priority == hooks[groupStart].Priority {
+			groupEnd++
+		}
+
+		if !s.runShutdownHookGroup(ctx, run, groupStart, groupEnd) {
+			report.TimedOut = true
+			break
+		}
+		groupStart = groupEnd
+	}
+
+	report.Hooks = run.snapshot()
+	report.Duration = time.Since(start)
+	s.logShutdownReport(report)
+	s.setShutdownReport(report)
+	return report
+}
+
+// LastShutdownReport returns the result of the last execution of the shutdown hooks, nil if they have not run.
+func (s *Server) LastShutdownReport() *ShutdownReport {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	return s.shutdownReport
+}
+
+func (s *Server) setShutdownReport(report *ShutdownReport) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.shutdownReport = report
+}
+
+// runShutdownHookGroup runs hooks[start:end] concurrently and waits for them. It returns false if ctx is done first.
+func (s *Server) runShutdownHookGroup(ctx context.Context, run *hookRun, start, end int) bool {
+	var wg sync.WaitGroup
+	for i := start; i < end; i++ {
+		hook := run.hooks[i]
+		if !s.shouldRunShutdownHook(hook) {
+			s.logger.Info("Skipping hook, its startup hook did not complete",
+				logger.String("name", hook.Name), logger.String("startup_hook", hook.StartupHook))
+			run.skip(i)
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, h ShutdownHook) {
+			defer wg.Done()
+			if !run.waitDependencies(ctx, h) {
+				return // Overall timeout, reported as skipped
+			}
+
+			s.logger.Info("Executing hook", logger.String("name", h.Name), logger.Int("priority", h.Priority))
+			run.start(i)
+			err := runHook(ctx, h.Timeout, h.Hook)
+			duration := run.finish(i, err)
+
+			if err != nil {
+				s.logger.Error("Hook failed", logger.String("name", h.Name), logger.Duration("duration", duration), logger.Error(err))
+			} else {
+				s.logger.Info("Hook completed", logger.String("name", h.Name), logger.Duration("duration", duration))
+			}
+		}(i, hook)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (s *Server) logShutdownReport(report *ShutdownReport) {
+	fields := []logger.Field{
+		logger.Duration("duration", report.Duration),
+		logger.Bool("timed_out", report.TimedOut),
+		logger.Array("hooks", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for i := range report.Hooks {
+				if err := arr.AppendObject(&report.Hooks[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})),
+	}
+	if err := report.Err(); err != nil {
+		s.logger.Error("Some shutdown hooks failed", append(fields, logger.Error(err))...)
+		return
+	}
+	s.logger.Info("All shutdown hooks completed", fields...)
+}
+
+// hookRun tracks the state of the hooks during a single execution of the shutdown hooks.
+type hookRun struct {
+	hooks  ShutdownHooks
+	done   []chan struct{}  // Closed when the hook at the same index has finished or was skipped
+	byName map[string][]int // Hook indices by name, for dependencies
+
+	mu       sync.Mutex
+	results  []HookResult
+	started  []time.Time // Zero until the hook starts running
+	finished []bool
+}
+
+func newHookRun(hooks ShutdownHooks) *hookRun {
+	run := &hookRun{
+		hooks:    hooks,
+		done:     make([]chan struct{}, len(hooks)),
+		byName:   make(map[string][]int, len(hooks)),
+		results:  make([]HookResult, len(hooks)),
+		started:  make([]time.Time, len(hooks)),
+		finished: make([]bool, len(hooks)),
+	}
+	for i, hook := range hooks {
+		run.done[i] = make(chan struct{})
+		run.byName[hook.Name] = append(run.byName[hook.Name], i)
+		run.results[i] = HookResult{Name: hook.Name, Priority: hook.Priority, Skipped: true}
+	}
+	return run
+}
+
+// waitDependencies waits for the dependencies of hook. It returns false if ctx is done first.
+func (r *hookRun) waitDependencies(ctx context.Context, hook ShutdownHook) bool {
+	for _, dep := range hook.DependsOn {
+		for _, i := range r.byName[dep] {
+			select {
+			case <-r.done[i]:
+			case <-ctx.Done():
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// start records that the hook at index i starts running.
+func (r *hookRun) start(i int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started[i] = time.Now()
+	r.results[i].Skipped = false
+}
+
+// finish records the result of the hook at index i and returns its duration.
+func (r *hookRun) finish(i int, err error) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results[i].Duration = time.Since(r.started[i])
+	r.results[i].Err = err
+	r.finished[i] = true
+	close(r.done[i])
+	return r.results[i].Duration
+}
+
+// skip records that the hook at index i does not run.
+func (r *hookRun) skip(i int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.finished[i] = true
+	close(r.done[i])
+}
+
+// snapshot returns the results; hooks still running are reported as interrupted by the shutdown timeout.
+func (r *hookRun) snapshot() []HookResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	results := make([]HookResult, len(r.results))
+	copy(results, r.results)
+	for i := range results {
+		if !r.finished[i] && !r.started[i].IsZero() {
+			results[i].Duration = time.Since(r.started[i])
+			results[i].Err = ErrShutdownTimeout
+		}
+	}
+	return results
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler for structured logging
+func (h *HookResult) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", h.Name)
+	enc.AddInt("priority", h.Priority)
+	enc.AddDuration("duration", h.Duration)
+	enc.AddBool("skipped", h.Skipped)
+	if h.Err != nil {
+		enc.AddString("error", h.Err.Error())
+	}
+	return nil
+}
+
+// validateShutdownHooks checks that dependencies exist, do not have a higher Priority than their dependents
+// and do not form cycles.
+func validateShutdownHooks(hooks ShutdownHooks) error {
+	byName := make(map[string][]ShutdownHook, len(hooks))
+	for _, hook := range hooks {
+		byName[hook.Name] = append(byName[hook.Name], hook)
+	}
+
+	for _, hook := range hooks {
+		for _, dep := range hook.DependsOn {
+			deps, ok := byName[dep]
+			if !ok {
+				return fmt.Errorf("shutdown hook %s depends on unknown hook %s", hook.Name, dep)
+			}
+			for _, d := range deps {
+				if d.Priority > hook.Priority {
+					return fmt.Errorf("shutdown hook %s (priority %d) depends on %s which runs later (priority %d)",
+						hook.Name, hook.Priority, dep, d.Priority)
+				}
+			}
+		}
+	}
+
+	// Detect cycles with a depth-first search
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(byName))
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("shutdown hook dependency cycle through %s", name)
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, hook := range byName[name] {
+			for _, dep := range hook.DependsOn {
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+	for name := range byName {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
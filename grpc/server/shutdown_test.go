@@ -0,0 +1,112 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rainbow-me/platform-tools/grpc/server"
+)
+
+func TestServer_RunShutdownHooks(t *testing.T) {
+	recorder := &hookRecorder{}
+	slowHook := func(name string) func(context.Context) error {
+		return func(ctx context.Context) error {
+			time.Sleep(50 * time.Millisecond)
+			return recorder.hook(name, nil)(ctx)
+		}
+	}
+	errFlush := errors.New("flush failed")
+
+	srv, err := server.NewServer(
+		server.WithShutdownHook(server.ShutdownHook{Name: "close-db", Priority: 2, Hook: recorder.hook("close-db", nil)}),
+		server.WithShutdownHook(server.ShutdownHook{Name: "stop-consumers", Priority: 1, Hook: slowHook("stop-consumers")}),
+		server.WithShutdownHook(server.ShutdownHook{
+			Name:      "close-cache",
+			Priority:  2,
+			DependsOn: []string{"flush-kafka"},
+			Hook:      recorder.hook("close-cache", nil),
+		}),
+		server.WithShutdownHook(server.ShutdownHook{Name: "flush-kafka", Priority: 2, Hook: func(ctx context.Context) error {
+			time.Sleep(50 * time.Millisecond)
+			return recorder.hook("flush-kafka", errFlush)(ctx)
+		}}),
+		server.WithShutdownHook(server.ShutdownHook{
+			Name:     "hang",
+			Priority: 3,
+			Timeout:  50 * time.Millisecond,
+			Hook: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+		}),
+	)
+	require.NoError(t, err)
+
+	report := srv.RunShutdownHooks(context.Background())
+	require.False(t, report.TimedOut)
+	require.Same(t, report, srv.LastShutdownReport())
+
+	// Priority 1 completes before priority 2 starts, and close-cache waits for flush-kafka
+	events := recorder.recorded()
+	require.Len(t, events, 4)
+	require.Equal(t, "stop-consumers", events[0])
+	require.Equal(t, "close-db", events[1])
+	require.Equal(t, []string{"flush-kafka", "close-cache"}, events[2:])
+
+	require.Len(t, report.Hooks, 5)
+	results := make(map[string]server.HookResult, len(report.Hooks))
+	for _, result := range report.Hooks {
+		results[result.Name] = result
+	}
+	require.GreaterOrEqual(t, results["stop-consumers"].Duration, 50*time.Millisecond)
+	require.ErrorIs(t, results["flush-kafka"].Err, errFlush)
+	require.NoError(t, results["close-cache"].Err)
+	require.ErrorIs(t, results["hang"].Err, server.ErrHookTimeout)
+
+	err = report.Err()
+	require.ErrorIs(t, err, errFlush)
+	require.ErrorIs(t, err, server.ErrHookTimeout)
+}
+
+func TestNewServer_InvalidShutdownHookDependencies(t *testing.T) {
+	noop := func(context.Context) error { return nil }
+
+	tests := []struct {
+		name  string
+		hooks []server.ShutdownHook
+	}{
+		{
+			name:  "unknown dependency",
+			hooks: []server.ShutdownHook{{Name: "a", DependsOn: []string{"missing"}, Hook: noop}},
+		},
+		{
+			name: "dependency runs later",
+			hooks: []server.ShutdownHook{
+				{Name: "a", Priority: 1, DependsOn: []string{"b"}, Hook: noop},
+				{Name: "b", Priority: 2, Hook: noop},
+			},
+		},
+		{
+			name: "cycle",
+			hooks: []server.ShutdownHook{
+				{Name: "a", DependsOn: []string{"b"}, Hook: noop},
+				{Name: "b", DependsOn: []string{"a"}, Hook: noop},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := make([]server.Option, 0, len(tt.hooks))
+			for _, hook := range tt.hooks {
+				opts = append(opts, server.WithShutdownHook(hook))
+			}
+			_, err := server.NewServer(opts...)
+			require.Error(t, err)
+		})
+	}
+}
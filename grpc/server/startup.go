@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/rainbow-me/platform-tools/common/logger"
+)
+
+// WithStartupHook adds a startup hook to be executed before any listener opens.
+// Hooks with the same priority run concurrently, priorities run in order. If a hook fails, Serve aborts
+// before binding ports.
+func WithStartupHook(hook StartupHook) Option {
+	return func(s *Server) error {
+		if hook.Hook == nil {
+			return fmt.Errorf("startup hook %s has no function", hook.Name)
+		}
+		if hook.Timeout == 0 {
+			hook.Timeout = DefaultStartupHookTimeout
+		}
+		s.startupHooks = append(s.startupHooks, hook)
+		return nil
+	}
+}
+
+// executeStartupHooks executes the startup hooks, priority group by priority group, stopping at the first
+// group with a failure. Hooks are cancelled when shutdown is requested.
+func (s *Server) executeStartupHooks(ctx context.Context) error {
+	if len(s.startupHooks) == 0 {
+		return nil
+	}
+
+	s.logger.Info("Executing startup hooks", logger.Int("count", len(s.startupHooks)))
+
+	sort.Stable(s.startupHooks)
+
+	for start := 0; start < len(s.startupHooks); {
+		end := start
+		for end < len(s.startupHooks) && s.startupHooks[end].Priority == s.startupHooks[start].Priority {
+			end++
+		}
+		if err := s.executeStartupHookGroup(ctx, s.startupHooks[start:end]); err != nil {
+			return err
+		}
+		start = end
+	}
+
+	s.logger.Info("All startup hooks completed")
+	return nil
+}
+
+// executeStartupHookGroup executes hooks of the same priority concurrently.
+func (s *Server) executeStartupHookGroup(ctx context.Context, hooks StartupHooks) error {
+	var wg sync.WaitGroup
+	var hookErrs []error
+	var mu sync.Mutex // For safe append
+
+	for _, hook := range hooks {
+		wg.Add(1)
+		go func(h StartupHook) {
+			defer wg.Done()
+
+			s.logger.Info("Executing startup hook", logger.String("name", h.Name), logger.Int("priority", h.Priority))
+
+			start := time.Now()
+			if err := runHook(ctx, h.Timeout, h.Hook); err != nil {
+				s.logger.Error("Startup hook failed", logger.String("name", h.Name), logger.Error(err))
+				mu.Lock()
+				hookErrs = append(hookErrs, fmt.Errorf("startup hook %s failed: %w", h.Name, err))
+				mu.Unlock()
+				return
+			}
+
+			s.markStartupHookCompleted(h.Name)
+			s.logger.Info("Startup hook completed", logger.String("name", h.Name), logger.Duration("duration", time.Since(start)))
+		}(hook)
+	}
+
+	wg.Wait()
+	return errors.Join(hookErrs...)
+}
+
+// runHook runs hook with the given timeout, returning as soon as the timeout expires even if the hook
+// does not honor its context.
+func runHook(ctx context.Context, timeout time.Duration, hook func(context.Context) error) error {
+	hookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- hook(hookCtx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-hookCtx.Done():
+		if errors.Is(hookCtx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %s", ErrHookTimeout, timeout)
+		}
+		return hookCtx.Err()
+	}
+}
+
+func (s *Server) markStartupHookCompleted(name string) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.completedStartupHooks[name] = struct{}{}
+}
+
+// shouldRunShutdownHook reports whether the startup hook the shutdown hook depends on, if any, has completed.
+func (s *Server) shouldRunShutdownHook(hook ShutdownHook) bool {
+	if hook.StartupHook == "" {
+		return true
+	}
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	_, completed := s.completedStartupHooks[hook.StartupHook]
+	return completed
+}
+
+// abortStartup runs the shutdown hooks of the parts already started after a startup hook failure, and
+// prevents any later shutdown.
+func (s *Server) abortStartup(startupErr error) error {
+	s.logger.Error("Startup aborted", logger.Error(startupErr))
+
+	err := startupErr
+	s.shutdownOnce.Do(func() {
+		s.health.markShuttingDown()
+		s.shutdownCancel()
+		s.emit(Event{Type: EventStopping})
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+		defer cancel()
+		if hookErr := s.ExecuteShutdownHooks(ctx); hookErr != nil {
+			err = errors.Join(err, hookErr)
+		}
+	})
+	return err
+}
@@ -0,0 +1,97 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rainbow-me/platform-tools/grpc/server"
+)
+
+type hookRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *hookRecorder) hook(name string, err error) func(context.Context) error {
+	return func(_ context.Context) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = append(r.events, name)
+		return err
+	}
+}
+
+func (r *hookRecorder) recorded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func TestServer_StartupHooks(t *testing.T) {
+	recorder := &hookRecorder{}
+	srv, err := server.NewServer(
+		server.WithHTTPServer("test-http", ":0", http.NewServeMux()),
+		server.WithAutomaticStop(false),
+		server.WithSignalHandling(false),
+		server.WithStartupHook(server.StartupHook{Name: "warmup", Priority: 2, Hook: recorder.hook("warmup", nil)}),
+		server.WithStartupHook(server.StartupHook{Name: "db", Priority: 1, Hook: recorder.hook("db", nil)}),
+		server.WithStartupHook(server.StartupHook{Name: "cache", Priority: 1, Hook: recorder.hook("cache", nil)}),
+	)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+	require.Eventually(t, func() bool { return srv.Health().IsServing("") }, time.Second, 10*time.Millisecond)
+
+	events := recorder.recorded()
+	require.Len(t, events, 3)
+	require.ElementsMatch(t, []string{"db", "cache"}, events[:2])
+	require.Equal(t, "warmup", events[2])
+
+	require.NoError(t, srv.Stop())
+	require.NoError(t, <-done)
+}
+
+func TestServer_StartupHookFailure(t *testing.T) {
+	errMigration := errors.New("migration failed")
+	recorder := &hookRecorder{}
+	srv, err := server.NewServer(
+		server.WithHTTPServer("test-http", ":0", http.NewServeMux()),
+		server.WithSignalHandling(false),
+		server.WithStartupHook(server.StartupHook{Name: "db", Priority: 1, Hook: recorder.hook("db", nil)}),
+		server.WithStartupHook(server.StartupHook{Name: "migrate", Priority: 2, Hook: recorder.hook("migrate", errMigration)}),
+		server.WithStartupHook(server.StartupHook{Name: "cache", Priority: 3, Hook: recorder.hook("cache", nil)}),
+		server.WithStartupHook(server.StartupHook{
+			Name:     "slow",
+			Priority: 2,
+			Timeout:  50 * time.Millisecond,
+			Hook: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}),
+		server.WithShutdownHook(server.ShutdownHook{Name: "close-db", StartupHook: "db", Hook: recorder.hook("close-db", nil)}),
+		server.WithShutdownHook(server.ShutdownHook{Name: "close-cache", StartupHook: "cache", Hook: recorder.hook("close-cache", nil)}),
+		server.WithShutdownHook(server.ShutdownHook{Name: "flush-logs", Priority: 1, Hook: recorder.hook("flush-logs", nil)}),
+	)
+	require.NoError(t, err)
+
+	err = srv.Serve()
+	require.ErrorIs(t, err, errMigration)
+	require.ErrorContains(t, err, "startup hook slow failed")
+	require.False(t, srv.Health().IsServing(""))
+
+	events := recorder.recorded()
+	require.NotContains(t, events, "cache")
+	require.NotContains(t, events, "close-cache")
+	require.Contains(t, events, "close-db")
+	require.Contains(t, events, "flush-logs")
+}
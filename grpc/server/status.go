@@ -0,0 +1,219 @@
+package server
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// ServerState is the lifecycle state of an HTTP or gRPC sub-server, or of a worker
+type ServerState string
+
+const (
+	StatePending   ServerState = "pending"   // Serve has not started it yet
+	StateStarting  ServerState = "starting"  // Creating the server and binding its address
+	StateListening ServerState = "listening" // Accepting connections
+	StateRunning   ServerState = "running"   // Running a worker
+	StateDraining  ServerState = "draining"  // Shutdown started, still serving in-flight requests or finishing work
+	StateStopped   ServerState = "stopped"   // Stopped by the Server
+	StateFailed    ServerState = "failed"    // Failed to listen or serve; it may be restarted by its policy
+)
+
+// Kinds of sub-servers reported by ServerStatus
+const (
+	KindHTTP   = "HTTP"
+	KindGRPC   = "gRPC"
+	KindWorker = "worker"
+)
+
+// ServerStatus is the state of a sub-server reported by Status
+type ServerStatus struct {
+	Name     string
+	Kind     string      // KindHTTP, KindGRPC or KindWorker
+	State    ServerState // Current state
+	Since    time.Time   // When the current state was entered
+	Addr     net.Addr    // Bound address, nil until the server listened
+	Err      error       // Last failure, nil if none
+	Restarts int         // Number of restarts so far
+
+	Connections int64 // Open connections of HTTP and gRPC servers, without the in-process ones
+	Rejected    int64 // Connections refused over MaxConnections so far
+}
+
+// EventType identifies the lifecycle events of a Server
+type EventType string
+
+const (
+	EventStarted  EventType = "started"  // A sub-server listens or a worker runs; with an empty Server, all of them do
+	EventStopping EventType = "stopping" // Shutdown started; Server is empty
+	EventStopped  EventType = "stopped"  // A sub-server stopped; with an empty Server, Serve is about to return
+	EventError    EventType = "error"    // A sub-server failed to listen or serve, or a worker returned an error
+)
+
+// Event is passed to the listeners subscribed with OnStarted, OnStopping, OnStopped and OnError
+type Event struct {
+	Type   EventType
+	Server string    // Name of the sub-server, empty for events of the whole Server
+	Time   time.Time // When the event happened
+	Addr   net.Addr  // Bound address on EventStarted of a sub-server
+	Err    error     // Failure on EventError
+}
+
+// lifecycle holds the status of the sub-servers and the event listeners.
+type lifecycle struct {
+	mu        sync.Mutex
+	order     []string // Sub-server names in configuration order
+	statuses  map[string]*ServerStatus
+	listeners map[EventType][]func(Event)
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{
+		statuses:  make(map[string]*ServerStatus),
+		listeners: make(map[EventType][]func(Event)),
+	}
+}
+
+// add registers a pending sub-server.
+func (l *lifecycle) add(name, kind string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.order = append(l.order, name)
+	l.statuses[name] = &ServerStatus{Name: name, Kind: kind, State: StatePending, Since: time.Now()}
+}
+
+// Status returns the state of every sub-server, HTTP servers first, then gRPC servers and workers, in
+// configuration order.
+func (s *Server) Status() []ServerStatus {
+	s.lifecycle.mu.Lock()
+	statuses := make([]ServerStatus, 0, len(s.lifecycle.order))
+	for _, name := range s.lifecycle.order {
+		statuses = append(statuses, *s.lifecycle.statuses[name])
+	}
+	s.lifecycle.mu.Unlock()
+
+	for i := range statuses {
+		statuses[i].Addr = s.Addr(statuses[i].Name)
+		if conns, ok := s.conns[statuses[i].Name]; ok {
+			statuses[i].Connections, statuses[i].Rejected = conns.active.Load(), conns.rejected.Load()
+		}
+	}
+	return statuses
+}
+
+// WaitReady blocks until every sub-server listens, and returns ErrNotReady if one of them fails to or the Server
+// stops first, or the error of ctx. Unlike Ready, it does not wait forever when startup fails.
+func (s *Server) WaitReady(ctx context.Context) error {
+	select {
+	case <-s.ready:
+		return nil
+	case <-s.startupDone:
+	case <-s.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-s.ready:
+		return nil
+	default:
+		return ErrNotReady
+	}
+}
+
+// OnStarted calls fn every time a sub-server listens or a worker starts, including after a restart, then once all
+// of them did. Listeners are called synchronously and must not block; subscribe before calling Serve to receive
+// every event.
+func (s *Server) OnStarted(fn func(Event)) {
+	s.subscribe(EventStarted, fn)
+}
+
+// OnStopping calls fn when the shutdown of the Server starts, before the drain phase.
+func (s *Server) OnStopping(fn func(Event)) {
+	s.subscribe(EventStopping, fn)
+}
+
+// OnStopped calls fn every time a sub-server stops, then once every sub-server stopped.
+func (s *Server) OnStopped(fn func(Event)) {
+	s.subscribe(EventStopped, fn)
+}
+
+// OnError calls fn every time a sub-server fails to listen or serve, whether or not it is restarted.
+func (s *Server) OnError(fn func(Event)) {
+	s.subscribe(EventError, fn)
+}
+
+func (s *Server) subscribe(eventType EventType, fn func(Event)) {
+	s.lifecycle.mu.Lock()
+	defer s.lifecycle.mu.Unlock()
+	s.lifecycle.listeners[eventType] = append(s.lifecycle.listeners[eventType], fn)
+}
+
+// emit calls the listeners of the event, outside the lock so that they can query the Server.
+func (s *Server) emit(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	s.lifecycle.mu.Lock()
+	listeners := s.lifecycle.listeners[event.Type]
+	s.lifecycle.mu.Unlock()
+	for _, fn := range listeners {
+		fn(event)
+	}
+}
+
+// setState moves the named sub-server to state and emits the matching event. err is recorded on StateFailed.
+func (s *Server) setState(name string, state ServerState, err error) {
+	now := time.Now()
+	s.lifecycle.mu.Lock()
+	status := s.lifecycle.statuses[name]
+	if (state == StateListening || state == StateRunning) && s.shutdownCtx.Err() != nil {
+		state = StateDraining // Bound while shutdown started, stopped soon
+	}
+	status.State, status.Since = state, now
+	if state == StateFailed {
+		status.Err = err
+	}
+	s.lifecycle.mu.Unlock()
+
+	switch state {
+	case StateListening, StateRunning:
+		s.emit(Event{Type: EventStarted, Server: name, Time: now, Addr: s.Addr(name)})
+	case StateFailed:
+		s.emit(Event{Type: EventError, Server: name, Time: now, Err: err})
+	case StateStopped:
+		s.emit(Event{Type: EventStopped, Server: name, Time: now})
+	default:
+	}
+}
+
+// countRestart records a restart of the named sub-server.
+func (s *Server) countRestart(name string) {
+	s.lifecycle.mu.Lock()
+	defer s.lifecycle.mu.Unlock()
+	s.lifecycle.statuses[name].Restarts++
+}
+
+// markDraining moves the listening sub-servers and running workers to StateDraining once shutdown starts.
+func (s *Server) markDraining() {
+	now := time.Now()
+	s.lifecycle.mu.Lock()
+	defer s.lifecycle.mu.Unlock()
+	for _, status := range s.lifecycle.statuses {
+		if status.State == StateListening || status.State == StateRunning {
+			status.State, status.Since = StateDraining, now
+		}
+	}
+}
+
+// markNotStarted moves the sub-servers Serve never started to StateStopped, e.g. when a startup hook fails.
+func (s *Server) markNotStarted() {
+	now := time.Now()
+	s.lifecycle.mu.Lock()
+	defer s.lifecycle.mu.Unlock()
+	for _, status := range s.lifecycle.statuses {
+		if status.State == StatePending {
+			status.State, status.Since = StateStopped, now
+		}
+	}
+}
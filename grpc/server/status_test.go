@@ -0,0 +1,134 @@
+package server_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/rainbow-me/platform-tools/grpc/server"
+)
+
+// eventRecorder subscribes to every event of a Server.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []server.Event
+}
+
+func recordEvents(srv *server.Server) *eventRecorder {
+	recorder := &eventRecorder{}
+	record := func(event server.Event) {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		recorder.events = append(recorder.events, event)
+	}
+	srv.OnStarted(record)
+	srv.OnStopping(record)
+	srv.OnStopped(record)
+	srv.OnError(record)
+	return recorder
+}
+
+// names returns "type:server" for the recorded events.
+func (r *eventRecorder) names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.events))
+	for _, event := range r.events {
+		names = append(names, string(event.Type)+":"+event.Server)
+	}
+	return names
+}
+
+func TestServer_Status(t *testing.T) {
+	srv, err := server.NewServer(
+		server.WithHTTPServer("http", ":0", http.NewServeMux()),
+		server.WithGRPCServer("grpc", ":0", nil, func(_ *grpc.Server) {}),
+		server.WithAutomaticStop(false),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+	recorder := recordEvents(srv)
+
+	for _, status := range srv.Status() {
+		require.Equal(t, server.StatePending, status.State)
+		require.Nil(t, status.Addr)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, srv.WaitReady(ctx))
+
+	statuses := srv.Status()
+	require.Len(t, statuses, 2)
+	require.Equal(t, "http", statuses[0].Name)
+	require.Equal(t, server.KindHTTP, statuses[0].Kind)
+	require.Equal(t, "grpc", statuses[1].Name)
+	require.Equal(t, server.KindGRPC, statuses[1].Kind)
+	for _, status := range statuses {
+		require.Equal(t, server.StateListening, status.State)
+		require.Equal(t, srv.Addr(status.Name), status.Addr)
+		require.NoError(t, status.Err)
+	}
+	require.ElementsMatch(t, []string{"started:http", "started:grpc", "started:"}, recorder.names())
+	require.Equal(t, "started:", recorder.names()[2])
+
+	require.NoError(t, srv.GracefulShutdown(context.Background()))
+	require.NoError(t, <-done)
+
+	for _, status := range srv.Status() {
+		require.Equal(t, server.StateStopped, status.State)
+	}
+	names := recorder.names()[3:]
+	require.ElementsMatch(t, []string{"stopping:", "stopped:http", "stopped:grpc", "stopped:"}, names)
+	require.Equal(t, "stopping:", names[0])
+	require.Equal(t, "stopped:", names[3])
+}
+
+func TestServer_WaitReadyFailure(t *testing.T) {
+	blocker := occupyPort(t)
+
+	srv, err := server.NewServer(
+		server.WithHTTPServer("http", ":0", http.NewServeMux()),
+		server.WithHTTPServer("taken", blocker.Addr().String(), http.NewServeMux()),
+		server.WithAutomaticStop(false),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+	recorder := recordEvents(srv)
+
+	// Not started yet
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, srv.WaitReady(ctx), context.DeadlineExceeded)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.ErrorIs(t, srv.WaitReady(ctx), server.ErrNotReady)
+
+	statuses := srv.Status()
+	require.Equal(t, server.StateListening, statuses[0].State)
+	require.Equal(t, server.StateFailed, statuses[1].State)
+	require.ErrorContains(t, statuses[1].Err, "HTTP server taken listen error")
+	require.Contains(t, recorder.names(), "error:taken")
+	require.NotContains(t, recorder.names(), "started:")
+
+	require.NoError(t, srv.Stop())
+	require.ErrorContains(t, <-done, "listen error")
+}
@@ -0,0 +1,102 @@
+package server_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/rainbow-me/platform-tools/common/test"
+	"github.com/rainbow-me/platform-tools/grpc/health"
+	"github.com/rainbow-me/platform-tools/grpc/server"
+	"github.com/rainbow-me/platform-tools/grpc/tlsconfig"
+)
+
+func TestServer_TLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := test.NewCA(t)
+	caFile := ca.WriteBundle(t, dir)
+	serverCert, serverKey := ca.Issue(t, dir, "server", 2)
+	clientCert, clientKey := ca.Issue(t, dir, "client", 3)
+
+	serverCerts, err := tlsconfig.Load(tlsconfig.Config{CertFile: serverCert, KeyFile: serverKey, CAFile: caFile})
+	require.NoError(t, err)
+	clientCerts, err := tlsconfig.Load(tlsconfig.Config{CertFile: clientCert, KeyFile: clientKey, CAFile: caFile})
+	require.NoError(t, err)
+
+	srv, err := server.NewServer(
+		server.WithHTTPServer("https", ":0", http.NewServeMux(),
+			server.WithHTTPTLS(serverCerts), server.WithHTTPHealthProbes()),
+		server.WithGRPCConfig(server.GRPCConfig{
+			Name:      "grpc",
+			Address:   ":0",
+			TLS:       serverCerts,
+			SetupFunc: func(_ *grpc.Server) {},
+		}),
+		server.WithAutomaticStop(false),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+	<-srv.Ready()
+
+	// HTTPS with a client certificate
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientCerts.ClientTLS()}}
+	resp, err := client.Get("https://" + localhost(srv.Addr("https")) + server.ReadinessPath)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Without a client certificate the handshake is rejected
+	anonymous, err := tlsconfig.Load(tlsconfig.Config{CAFile: caFile})
+	require.NoError(t, err)
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: anonymous.ClientTLS()}}
+	_, err = client.Get("https://" + localhost(srv.Addr("https")) + server.ReadinessPath)
+	require.Error(t, err)
+
+	// gRPC over mTLS with the health checker
+	checker, err := health.NewHealthChecker(
+		health.WithTarget(localhost(srv.Addr("grpc"))),
+		health.WithTLSConfig(clientCerts.ClientTLS()),
+	)
+	require.NoError(t, err)
+	res, err := checker.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, res.GetStatus())
+	require.NoError(t, checker.Close())
+
+	require.NoError(t, srv.GracefulShutdown(context.Background()))
+	require.NoError(t, <-done)
+}
+
+func TestServer_TLSWithGRPCServer(t *testing.T) {
+	dir := t.TempDir()
+	ca := test.NewCA(t)
+	certFile, keyFile := ca.Issue(t, dir, "server", 2)
+	certs, err := tlsconfig.Load(tlsconfig.Config{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+
+	_, err = server.NewServer(server.WithGRPCConfig(server.GRPCConfig{
+		Name:       "grpc",
+		Address:    ":0",
+		GRPCServer: grpc.NewServer(),
+		TLS:        certs,
+	}))
+	require.Error(t, err)
+}
+
+// localhost returns the address on localhost matching the certificates of test.CA
+func localhost(addr net.Addr) string {
+	_, port, _ := net.SplitHostPort(addr.String())
+	return net.JoinHostPort("localhost", port)
+}
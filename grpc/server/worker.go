@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/rainbow-me/platform-tools/common/logger"
+	"github.com/rainbow-me/platform-tools/grpc/scheduler"
+)
+
+// WorkerConfig holds configuration for background workers, e.g. queue consumers or pollers
+type WorkerConfig struct {
+	Name    string                      // Unique name for this worker (used in logging and status)
+	Run     func(context.Context) error // Runs until its context is done; the context is cancelled when shutdown starts
+	Restart RestartPolicy               // Whether and how to restart the worker when it returns an error
+}
+
+// WithWorker runs fn in the background while the Server serves. fn receives a context cancelled when shutdown
+// starts and should return once it is done, with nil or the context error. Returning nil earlier completes the
+// worker; returning an error, or panicking, is a failure handled like a server failure: the worker is restarted
+// according to policy, and otherwise stops the Server unless automatic stop is disabled or the policy is Optional.
+// Graceful shutdown waits for the workers to return before running the shutdown hooks.
+func WithWorker(name string, fn func(ctx context.Context) error, policy RestartPolicy) Option {
+	return func(s *Server) error {
+		if fn == nil {
+			return fmt.Errorf("worker %s has no function", name)
+		}
+		s.workerConfigs = append(s.workerConfigs, WorkerConfig{Name: name, Run: fn, Restart: policy})
+		return nil
+	}
+}
+
+// WithScheduler runs the jobs of sched as a worker named name: runs start once Serve is called, and graceful
+// shutdown cancels the running jobs and waits for them to return before running the shutdown hooks.
+func WithScheduler(name string, sched *scheduler.Scheduler) Option {
+	if sched == nil {
+		return func(*Server) error {
+			return fmt.Errorf("scheduler %s is nil", name)
+		}
+	}
+	return WithWorker(name, sched.Run, RestartPolicy{})
+}
+
+// startWorker runs a worker in a goroutine, restarting it according to its policy
+func (s *Server) startWorker(config WorkerConfig) {
+	s.wg.Add(1)
+	s.workersWG.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.workersWG.Done()
+		s.runSupervised(KindWorker, config.Name, config.Restart, func(running func()) error {
+			return s.runWorker(config, running)
+		})
+	}()
+}
+
+// runWorker runs the worker once. It returns nil when the worker completed or stopped on shutdown.
+func (s *Server) runWorker(config WorkerConfig, running func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Worker panicked", append(logger.WithPanic(r), logger.String("name", config.Name))...)
+			err = errors.Newf("worker %s panicked: %v", config.Name, r)
+		}
+	}()
+
+	s.logger.Info("Starting worker", logger.String("name", config.Name))
+	running()
+
+	err = config.Run(s.shutdownCtx)
+	if err != nil && (s.shutdownCtx.Err() == nil || !errors.Is(err, context.Canceled)) {
+		s.logger.Error("Worker error", logger.String("name", config.Name), logger.Error(err))
+		return fmt.Errorf("worker %s error: %w", config.Name, err)
+	}
+	s.logger.Info("Worker stopped", logger.String("name", config.Name))
+	return nil
+}
+
+// waitWorkers waits for the workers to return after the cancellation of their context, or for ctx to be done.
+func (s *Server) waitWorkers(ctx context.Context) error {
+	if len(s.workerConfigs) == 0 {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.workersWG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		var running []string
+		for _, status := range s.Status() {
+			if status.Kind == KindWorker && status.State == StateDraining {
+				running = append(running, status.Name)
+			}
+		}
+		return fmt.Errorf("workers still running at the shutdown timeout: %s", strings.Join(running, ", "))
+	}
+}
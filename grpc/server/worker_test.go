@@ -0,0 +1,215 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rainbow-me/platform-tools/grpc/scheduler"
+	"github.com/rainbow-me/platform-tools/grpc/server"
+)
+
+func TestServer_Worker(t *testing.T) {
+	var hookAfterWorker atomic.Bool
+	var workerDone atomic.Bool
+	srv, err := server.NewServer(
+		server.WithHTTPServer("http", ":0", http.NewServeMux()),
+		server.WithWorker("consumer", func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond) // Finish the current message
+			workerDone.Store(true)
+			return ctx.Err()
+		}, server.RestartPolicy{}),
+		server.WithShutdownHook(server.ShutdownHook{Name: "queue", Hook: func(context.Context) error {
+			hookAfterWorker.Store(workerDone.Load())
+			return nil
+		}}),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+	recorder := recordEvents(srv)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+	require.NoError(t, srv.WaitReady(context.Background()))
+
+	statuses := srv.Status()
+	require.Len(t, statuses, 2)
+	require.Equal(t, "consumer", statuses[1].Name)
+	require.Equal(t, server.KindWorker, statuses[1].Kind)
+	require.Equal(t, server.StateRunning, statuses[1].State)
+	require.Nil(t, statuses[1].Addr)
+	require.Contains(t, recorder.names(), "started:consumer")
+
+	require.NoError(t, srv.GracefulShutdown(context.Background()))
+	require.NoError(t, <-done)
+	require.True(t, hookAfterWorker.Load())
+	require.Equal(t, server.StateStopped, srv.Status()[1].State)
+	require.Contains(t, recorder.names(), "stopped:consumer")
+}
+
+func TestServer_WorkerFailure(t *testing.T) {
+	srv, err := server.NewServer(
+		server.WithWorker("poller", func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}, server.RestartPolicy{}),
+		server.WithWorker("broken", func(context.Context) error {
+			panic("nil map")
+		}, server.RestartPolicy{}),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	// The panic of the worker without a policy stops the Server and the other worker
+	err = srv.Serve()
+	require.ErrorContains(t, err, "worker broken panicked: nil map")
+
+	statuses := srv.Status()
+	require.Equal(t, server.StateFailed, statuses[1].State)
+	require.Equal(t, server.StateStopped, statuses[0].State)
+}
+
+func TestServer_WorkerRestart(t *testing.T) {
+	var runs atomic.Int32
+	srv, err := server.NewServer(
+		server.WithWorker("flaky", func(ctx context.Context) error {
+			if runs.Add(1) < 3 {
+				return errors.New("connection reset")
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		}, server.RestartPolicy{Mode: server.RestartOnFailure, InitialBackoff: time.Millisecond}),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+	require.NoError(t, srv.WaitReady(context.Background()))
+	require.Eventually(t, func() bool { return srv.Status()[0].State == server.StateRunning && runs.Load() == 3 },
+		time.Second, 5*time.Millisecond)
+	require.Equal(t, 2, srv.Status()[0].Restarts)
+	require.Len(t, srv.RestartEvents(), 2)
+
+	require.NoError(t, srv.GracefulShutdown(context.Background()))
+	require.NoError(t, <-done)
+}
+
+func TestServer_WorkerRestartAfterStablePeriod(t *testing.T) {
+	var runs atomic.Int32
+	srv, err := server.NewServer(
+		server.WithWorker("consumer", func(ctx context.Context) error {
+			switch runs.Add(1) {
+			case 1:
+				return errors.New("connection reset")
+			case 2:
+				time.Sleep(50 * time.Millisecond) // Up for longer than the stable period
+				return errors.New("connection reset")
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		}, server.RestartPolicy{
+			Mode:           server.RestartOnFailure,
+			MaxRestarts:    1,
+			InitialBackoff: time.Millisecond,
+			StablePeriod:   20 * time.Millisecond,
+		}),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+	require.NoError(t, srv.WaitReady(context.Background()))
+
+	// The second failure is not consecutive to the first one: restarted again, with the initial backoff
+	require.Eventually(t, func() bool { return srv.Status()[0].State == server.StateRunning && runs.Load() == 3 },
+		time.Second, 5*time.Millisecond)
+	events := srv.RestartEvents()
+	require.Len(t, events, 2)
+	for _, event := range events {
+		require.False(t, event.GaveUp)
+		require.Equal(t, 1, event.Restart)
+		require.Equal(t, time.Millisecond, event.Backoff)
+	}
+
+	require.NoError(t, srv.GracefulShutdown(context.Background()))
+	require.NoError(t, <-done)
+}
+
+func TestServer_WorkerShutdownTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	srv, err := server.NewServer(
+		server.WithWorker("stuck", func(context.Context) error {
+			<-release
+			return nil
+		}, server.RestartPolicy{}),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	go func() {
+		_ = srv.Serve()
+	}()
+	require.NoError(t, srv.WaitReady(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorContains(t, srv.GracefulShutdown(ctx), "workers still running at the shutdown timeout: stuck")
+}
+
+func TestServer_DuplicateWorkerName(t *testing.T) {
+	_, err := server.NewServer(
+		server.WithHTTPServer("main", ":0", http.NewServeMux()),
+		server.WithWorker("main", func(context.Context) error { return nil }, server.RestartPolicy{}),
+	)
+	require.ErrorContains(t, err, "duplicate worker name: main")
+}
+
+func TestServer_Scheduler(t *testing.T) {
+	ran := make(chan struct{}, 10)
+	sched := scheduler.New()
+	require.NoError(t, sched.Add(scheduler.Job{
+		Name:     "refresh",
+		Schedule: scheduler.Every(10 * time.Millisecond),
+		Run: func(context.Context) error {
+			ran <- struct{}{}
+			return nil
+		},
+	}))
+
+	srv, err := server.NewServer(
+		server.WithScheduler("jobs", sched),
+		server.WithSignalHandling(false),
+		server.WithShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve()
+	}()
+	<-ran
+	require.Equal(t, server.KindWorker, srv.Status()[0].Kind)
+
+	require.NoError(t, srv.GracefulShutdown(context.Background()))
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,226 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/rainbow-me/platform-tools/common/logger"
+)
+
+// DefaultReloadInterval is the minimum time between two checks of the certificate files
+var DefaultReloadInterval = 10 * time.Second
+
+// Config describes the TLS material shared by servers and clients
+type Config struct {
+	CertFile       string        // PEM certificate chain presented to peers
+	KeyFile        string        // PEM private key of CertFile
+	CAFile         string        // PEM CA bundle verifying peers: client certificates on servers (mTLS), servers on clients
+	MinVersion     uint16        // Minimum TLS version, tls.VersionTLS12 by default
+	ServerName     string        // Name expected in the server certificate on clients; defaults to the dialed host
+	ReloadInterval time.Duration // Minimum time between two checks of the files, DefaultReloadInterval by default
+}
+
+// Option is a functional option for configuring Certificates
+type Option func(*Certificates)
+
+// WithLogger sets the logger reporting certificate reloads
+func WithLogger(l *logger.Logger) Option {
+	return func(c *Certificates) {
+		c.logger = l
+	}
+}
+
+// Certificates holds the TLS material described by a Config and reloads it from disk when the files change,
+// so that rotated certificates are picked up by new connections without a restart. The same Certificates can
+// back servers and the clients connecting to them.
+type Certificates struct {
+	config Config
+	logger *logger.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	caPool    *x509.CertPool
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+}
+
+// Load reads the files described by config. Either both CertFile and KeyFile or neither must be set; a client
+// without them relies on CAFile, or the system roots, to verify the server.
+func Load(config Config, opts ...Option) (*Certificates, error) {
+	if (config.CertFile == "") != (config.KeyFile == "") {
+		return nil, errors.New("CertFile and KeyFile must be set together")
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+	if config.ReloadInterval == 0 {
+		config.ReloadInterval = DefaultReloadInterval
+	}
+
+	c := &Certificates{
+		config: config,
+		logger: logger.NoOp(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ServerTLS returns the tls.Config of a server. Clients must present a certificate signed by CAFile when it is set.
+func (c *Certificates) ServerTLS() *tls.Config {
+	config := c.serverTLS()
+	if c.config.CAFile != "" {
+		// The client CAs can only change per connection through GetConfigForClient
+		config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c.reloadIfChanged()
+			return c.serverTLS(), nil
+		}
+	}
+	return config
+}
+
+// ClientTLS returns the tls.Config of a client: it presents CertFile, if set, and verifies the server against
+// CAFile, or the system roots. The CA bundle is read once; the client certificate follows reloads.
+func (c *Certificates) ClientTLS() *tls.Config {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	config := &tls.Config{
+		MinVersion: c.config.MinVersion,
+		RootCAs:    c.caPool,
+		ServerName: c.config.ServerName,
+	}
+	if c.cert != nil {
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return c.certificate(), nil
+		}
+	}
+	return config
+}
+
+// ServerOption returns the grpc.ServerOption serving TLS, see ServerTLS
+func (c *Certificates) ServerOption() grpc.ServerOption {
+	return grpc.Creds(credentials.NewTLS(c.ServerTLS()))
+}
+
+// DialOption returns the grpc.DialOption connecting over TLS, see ClientTLS. It can be passed to
+// gateway.WithTLS or health.WithDialOptions.
+func (c *Certificates) DialOption() grpc.DialOption {
+	return grpc.WithTransportCredentials(credentials.NewTLS(c.ClientTLS()))
+}
+
+func (c *Certificates) serverTLS() *tls.Config {
+	config := &tls.Config{
+		MinVersion: c.config.MinVersion,
+		NextProtos: []string{"h2", "http/1.1"},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return c.certificate(), nil
+		},
+	}
+	if c.config.CAFile != "" {
+		c.mu.RLock()
+		config.ClientCAs = c.caPool
+		c.mu.RUnlock()
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config
+}
+
+func (c *Certificates) certificate() *tls.Certificate {
+	c.reloadIfChanged()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert
+}
+
+// reloadIfChanged reloads the material if a file changed since the last load, at most once per ReloadInterval.
+// On failure the previous material is kept.
+func (c *Certificates) reloadIfChanged() {
+	c.mu.Lock()
+	if time.Since(c.lastCheck) < c.config.ReloadInterval {
+		c.mu.Unlock()
+		return
+	}
+	c.lastCheck = time.Now()
+	changed := false
+	for file, modTime := range c.modTimes {
+		info, err := os.Stat(file)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			changed = true
+			break
+		}
+	}
+	c.mu.Unlock()
+
+	if changed {
+		_ = c.Reload() // Logged
+	}
+}
+
+// Reload reads the files now, without waiting for ReloadInterval or a change of their modification time, e.g. from
+// a reload hook after a sidecar rotated them. On failure the previous material is kept and the error returned.
+func (c *Certificates) Reload() error {
+	if err := c.load(); err != nil {
+		c.logger.Error("Failed to reload TLS certificates, keeping the previous ones", logger.Error(err))
+		return err
+	}
+	c.logger.Info("Reloaded TLS certificates", logger.String("cert_file", c.config.CertFile))
+	return nil
+}
+
+// load reads the files and replaces the material.
+func (c *Certificates) load() error {
+	modTimes := make(map[string]time.Time, 3)
+	for _, file := range []string{c.config.CertFile, c.config.KeyFile, c.config.CAFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", file, err)
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	var cert *tls.Certificate
+	if c.config.CertFile != "" {
+		pair, err := tls.LoadX509KeyPair(c.config.CertFile, c.config.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load certificate %s: %w", c.config.CertFile, err)
+		}
+		cert = &pair
+	}
+
+	var caPool *x509.CertPool
+	if c.config.CAFile != "" {
+		pem, err := os.ReadFile(c.config.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA bundle %s: %w", c.config.CAFile, err)
+		}
+		caPool = x509.NewCertPool()
+		if !caPool.AppendCertsFromPEM(pem) {
+			return errors.Newf("no certificate found in CA bundle %s", c.config.CAFile)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cert = cert
+	c.caPool = caPool
+	c.modTimes = modTimes
+	c.lastCheck = time.Now()
+	return nil
+}
@@ -0,0 +1,162 @@
+package tlsconfig_test
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rainbow-me/platform-tools/common/test"
+	"github.com/rainbow-me/platform-tools/grpc/tlsconfig"
+)
+
+// handshake serves a single TLS connection with server and returns the serial number of the certificate seen
+// by the client.
+func handshake(t *testing.T, server, client *tls.Config) (*big.Int, error) {
+	t.Helper()
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", server)
+	require.NoError(t, err)
+	defer lis.Close()
+
+	go func() {
+		conn, acceptErr := lis.Accept()
+		if acceptErr != nil {
+			return
+		}
+		defer conn.Close()
+		// Keep the connection open until the client closes it
+		_, _ = io.Copy(io.Discard, conn)
+	}()
+
+	conn, err := tls.Dial("tcp", lis.Addr().String(), client)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	// With TLS 1.3 a rejected client certificate is only reported on the first read
+	_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, err = conn.Read(make([]byte, 1)); err != nil {
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			return nil, err
+		}
+	}
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber, nil
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	ca := test.NewCA(t)
+	certFile, keyFile := ca.Issue(t, dir, "server", 2)
+
+	_, err := tlsconfig.Load(tlsconfig.Config{CertFile: certFile})
+	require.Error(t, err)
+
+	_, err = tlsconfig.Load(tlsconfig.Config{CertFile: certFile, KeyFile: filepath.Join(dir, "missing.key")})
+	require.Error(t, err)
+
+	certs, err := tlsconfig.Load(tlsconfig.Config{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS12), certs.ServerTLS().MinVersion)
+}
+
+func TestCertificates_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := test.NewCA(t)
+	caFile := ca.WriteBundle(t, dir)
+	serverCert, serverKey := ca.Issue(t, dir, "server", 2)
+	clientCert, clientKey := ca.Issue(t, dir, "client", 3)
+
+	server, err := tlsconfig.Load(tlsconfig.Config{CertFile: serverCert, KeyFile: serverKey, CAFile: caFile})
+	require.NoError(t, err)
+	client, err := tlsconfig.Load(tlsconfig.Config{CertFile: clientCert, KeyFile: clientKey, CAFile: caFile})
+	require.NoError(t, err)
+
+	serial, err := handshake(t, server.ServerTLS(), client.ClientTLS())
+	require.NoError(t, err)
+	require.Equal(t, int64(2), serial.Int64())
+
+	// A client without certificate is rejected
+	anonymous, err := tlsconfig.Load(tlsconfig.Config{CAFile: caFile})
+	require.NoError(t, err)
+	_, err = handshake(t, server.ServerTLS(), anonymous.ClientTLS())
+	require.Error(t, err)
+}
+
+func TestCertificates_Reload(t *testing.T) {
+	dir := t.TempDir()
+	ca := test.NewCA(t)
+	caFile := ca.WriteBundle(t, dir)
+	certFile, keyFile := ca.Issue(t, dir, "server", 2)
+
+	server, err := tlsconfig.Load(tlsconfig.Config{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ReloadInterval: time.Nanosecond,
+	})
+	require.NoError(t, err)
+	client, err := tlsconfig.Load(tlsconfig.Config{CAFile: caFile})
+	require.NoError(t, err)
+	serverTLS := server.ServerTLS()
+
+	serial, err := handshake(t, serverTLS, client.ClientTLS())
+	require.NoError(t, err)
+	require.Equal(t, int64(2), serial.Int64())
+
+	// Rotate the certificate on disk, the running config picks it up
+	ca.Issue(t, dir, "server", 4)
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	require.NoError(t, os.Chtimes(keyFile, future, future))
+
+	serial, err = handshake(t, serverTLS, client.ClientTLS())
+	require.NoError(t, err)
+	require.Equal(t, int64(4), serial.Int64())
+
+	// A broken file keeps the previous certificate
+	require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0o600))
+	future = future.Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+
+	serial, err = handshake(t, serverTLS, client.ClientTLS())
+	require.NoError(t, err)
+	require.Equal(t, int64(4), serial.Int64())
+}
+
+func TestCertificates_ForcedReload(t *testing.T) {
+	dir := t.TempDir()
+	ca := test.NewCA(t)
+	caFile := ca.WriteBundle(t, dir)
+	certFile, keyFile := ca.Issue(t, dir, "server", 2)
+
+	server, err := tlsconfig.Load(tlsconfig.Config{CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Hour})
+	require.NoError(t, err)
+	client, err := tlsconfig.Load(tlsconfig.Config{CAFile: caFile})
+	require.NoError(t, err)
+	serverTLS := server.ServerTLS()
+
+	// Rotated on disk, not checked before ReloadInterval
+	ca.Issue(t, dir, "server", 4)
+	serial, err := handshake(t, serverTLS, client.ClientTLS())
+	require.NoError(t, err)
+	require.Equal(t, int64(2), serial.Int64())
+
+	require.NoError(t, server.Reload())
+	serial, err = handshake(t, serverTLS, client.ClientTLS())
+	require.NoError(t, err)
+	require.Equal(t, int64(4), serial.Int64())
+
+	// A broken file keeps the previous certificate
+	require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0o600))
+	require.Error(t, server.Reload())
+	serial, err = handshake(t, serverTLS, client.ClientTLS())
+	require.NoError(t, err)
+	require.Equal(t, int64(4), serial.Int64())
+}